@@ -8,7 +8,10 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"envseed/internal/envseed"
 	"envseed/internal/version"
@@ -39,6 +42,12 @@ func main() {
 		handleError(runDiff(ctx, subArgs))
 	case "validate":
 		handleError(runValidate(ctx, subArgs))
+	case "import":
+		handleError(runImport(ctx, subArgs))
+	case "push":
+		handleError(runPush(ctx, subArgs))
+	case "agent":
+		handleError(runAgent(ctx, subArgs))
 	case "version":
 		handleError(runVersion(subArgs))
 	case "-h", "--help", "help":
@@ -51,18 +60,30 @@ func main() {
 
 func runSync(ctx context.Context, args []string) error {
 	var outputPath string
+	var profile string
 	var force bool
 	var dryRun bool
 	var quiet bool
+	var generateMissing bool
+	var passBackend string
+	var useAgent bool
+	var ageIdentity string
+	var jobs int
 
 	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
 	fs.StringVar(&outputPath, "output", "", "override the destination path")
 	fs.StringVar(&outputPath, "o", "", "override the destination path (shorthand)")
+	fs.StringVar(&profile, "profile", "", "template profile declared with #@vars (output defaults to .env.PROFILE)")
 	fs.BoolVar(&force, "force", false, "overwrite existing output files")
 	fs.BoolVar(&force, "f", false, "overwrite existing output files (shorthand)")
 	fs.BoolVar(&dryRun, "dry-run", false, "print redacted result instead of writing files")
 	fs.BoolVar(&quiet, "quiet", false, "suppress informational output")
 	fs.BoolVar(&quiet, "q", false, "suppress informational output (shorthand)")
+	fs.BoolVar(&generateMissing, "generate-missing", false, "create missing <pass:...> entries with random values")
+	fs.StringVar(&passBackend, "pass-backend", "", "pass backend: pass, gopass, or native (default $ENVSEED_PASS_BACKEND or pass)")
+	fs.BoolVar(&useAgent, "agent", false, "consult the envseed agent for pass values (default $ENVSEED_AGENT)")
+	fs.StringVar(&ageIdentity, "age-identity", "", "age identity file for <age:...> placeholders (default $ENVSEED_AGE_IDENTITY)")
+	fs.IntVar(&jobs, "jobs", envseed.DefaultJobs, "number of placeholders resolved concurrently")
 	fs.SetOutput(io.Discard)
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: envseed sync [flags] [INPUT_FILE]\n\nFlags:\n")
@@ -90,24 +111,50 @@ func runSync(ctx context.Context, args []string) error {
 	if inputPath == "-" {
 		return envseed.NewExitError("EVE-101-101")
 	}
+	if jobs < 1 {
+		return envseed.NewExitError("EVE-101-8", jobs)
+	}
+
+	client, err := passClient(passBackend, useAgent)
+	if err != nil {
+		return err
+	}
 
 	return envseed.Sync(ctx, envseed.SyncOptions{
-		InputPath:  inputPath,
-		OutputPath: outputPath,
-		Force:      force,
-		DryRun:     dryRun,
-		Quiet:      quiet,
-		Stdout:     os.Stdout,
-		Stderr:     os.Stderr,
+		InputPath:       inputPath,
+		OutputPath:      outputPath,
+		Profile:         profile,
+		Force:           force,
+		DryRun:          dryRun,
+		Quiet:           quiet,
+		GenerateMissing: generateMissing,
+		AgeIdentity:     ageIdentity,
+		Jobs:            jobs,
+		PassClient:      client,
+		Stdout:          os.Stdout,
+		Stderr:          os.Stderr,
 	})
 }
 
 func runDiff(ctx context.Context, args []string) error {
 	var outputPath string
+	var profile string
+	var quiet bool
+	var passBackend string
+	var useAgent bool
+	var ageIdentity string
+	var jobs int
 
 	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
 	fs.StringVar(&outputPath, "output", "", "override the destination path")
 	fs.StringVar(&outputPath, "o", "", "override the destination path (shorthand)")
+	fs.StringVar(&profile, "profile", "", "template profile declared with #@vars (output defaults to .env.PROFILE)")
+	fs.BoolVar(&quiet, "quiet", false, "suppress fallback warnings")
+	fs.BoolVar(&quiet, "q", false, "suppress fallback warnings (shorthand)")
+	fs.StringVar(&passBackend, "pass-backend", "", "pass backend: pass, gopass, or native (default $ENVSEED_PASS_BACKEND or pass)")
+	fs.BoolVar(&useAgent, "agent", false, "consult the envseed agent for pass values (default $ENVSEED_AGENT)")
+	fs.StringVar(&ageIdentity, "age-identity", "", "age identity file for <age:...> placeholders (default $ENVSEED_AGE_IDENTITY)")
+	fs.IntVar(&jobs, "jobs", envseed.DefaultJobs, "number of placeholders resolved concurrently")
 	fs.SetOutput(io.Discard)
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: envseed diff [flags] [INPUT_FILE]\n\nFlags:\n")
@@ -135,11 +182,23 @@ func runDiff(ctx context.Context, args []string) error {
 	if inputPath == "-" {
 		return envseed.NewExitError("EVE-101-101")
 	}
+	if jobs < 1 {
+		return envseed.NewExitError("EVE-101-8", jobs)
+	}
+	client, err := passClient(passBackend, useAgent)
+	if err != nil {
+		return err
+	}
 	result, err := envseed.Diff(ctx, envseed.DiffOptions{
-		InputPath:  inputPath,
-		OutputPath: outputPath,
-		Stdout:     os.Stdout,
-		Stderr:     os.Stderr,
+		InputPath:   inputPath,
+		OutputPath:  outputPath,
+		Profile:     profile,
+		Quiet:       quiet,
+		AgeIdentity: ageIdentity,
+		Jobs:        jobs,
+		PassClient:  client,
+		Stdout:      os.Stdout,
+		Stderr:      os.Stderr,
 	})
 	if err != nil {
 		return err
@@ -152,10 +211,16 @@ func runDiff(ctx context.Context, args []string) error {
 }
 
 func runValidate(ctx context.Context, args []string) error {
+	var profile string
+
 	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.StringVar(&profile, "profile", "", "check only this profile (default every profile declared with #@vars)")
 	fs.SetOutput(io.Discard)
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: envseed validate [INPUT_FILE]\n")
+		fmt.Fprintf(os.Stderr, "Usage: envseed validate [flags] [INPUT_FILE]\n\nFlags:\n")
+		fs.SetOutput(os.Stderr)
+		fs.PrintDefaults()
+		fs.SetOutput(io.Discard)
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -179,9 +244,235 @@ func runValidate(ctx context.Context, args []string) error {
 	}
 	return envseed.Validate(ctx, envseed.ValidateOptions{
 		InputPath: inputPath,
+		Profile:   profile,
+	})
+}
+
+func runImport(ctx context.Context, args []string) error {
+	var outputPath string
+	var prefix string
+	var keep stringList
+	var force bool
+	var quiet bool
+	var passBackend string
+	var useAgent bool
+
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.StringVar(&outputPath, "output", "", "template to write (default .envseed next to ENV_FILE)")
+	fs.StringVar(&outputPath, "o", "", "template to write (shorthand)")
+	fs.StringVar(&prefix, "prefix", "", "pass folder the values are stored under (required)")
+	fs.Var(&keep, "keep", "keep values of variables matching this name pattern literal (repeatable)")
+	fs.BoolVar(&force, "force", false, "overwrite an existing template")
+	fs.BoolVar(&force, "f", false, "overwrite an existing template (shorthand)")
+	fs.BoolVar(&quiet, "quiet", false, "suppress informational output")
+	fs.BoolVar(&quiet, "q", false, "suppress informational output (shorthand)")
+	fs.StringVar(&passBackend, "pass-backend", "", "pass backend: pass, gopass, or native (default $ENVSEED_PASS_BACKEND or pass)")
+	fs.BoolVar(&useAgent, "agent", false, "consult the envseed agent for pass values (default $ENVSEED_AGENT)")
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: envseed import --prefix PATH [flags] [ENV_FILE]\n\nFlags:\n")
+		fs.SetOutput(os.Stderr)
+		fs.PrintDefaults()
+		fs.SetOutput(io.Discard)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.Usage()
+			return exitRequest{code: envseed.ExitOK}
+		}
+		return envseed.NewExitError("EVE-101-5", err.Error())
+	}
+
+	if fs.NArg() > 1 {
+		return envseed.NewExitError("EVE-101-6")
+	}
+	if prefix == "" {
+		return envseed.NewExitError("EVE-101-9", "--prefix")
+	}
+
+	inputPath := ".env"
+	if fs.NArg() == 1 {
+		inputPath = fs.Arg(0)
+	}
+	if inputPath == "-" {
+		return envseed.NewExitError("EVE-101-101")
+	}
+	client, err := passClient(passBackend, useAgent)
+	if err != nil {
+		return err
+	}
+	return envseed.Import(ctx, envseed.ImportOptions{
+		InputPath:  inputPath,
+		OutputPath: outputPath,
+		Prefix:     prefix,
+		Keep:       keep,
+		Force:      force,
+		Quiet:      quiet,
+		PassClient: client,
+		Stderr:     os.Stderr,
 	})
 }
 
+func runPush(ctx context.Context, args []string) error {
+	var outputPath string
+	var profile string
+	var yes bool
+	var dryRun bool
+	var quiet bool
+	var passBackend string
+	var useAgent bool
+	var ageIdentity string
+	var jobs int
+
+	fs := flag.NewFlagSet("push", flag.ContinueOnError)
+	fs.StringVar(&outputPath, "output", "", "override the .env path")
+	fs.StringVar(&outputPath, "o", "", "override the .env path (shorthand)")
+	fs.StringVar(&profile, "profile", "", "template profile declared with #@vars (output defaults to .env.PROFILE)")
+	fs.BoolVar(&yes, "yes", false, "update the entries without asking")
+	fs.BoolVar(&yes, "y", false, "update the entries without asking (shorthand)")
+	fs.BoolVar(&dryRun, "dry-run", false, "list the entries that would be updated")
+	fs.BoolVar(&quiet, "quiet", false, "suppress informational output")
+	fs.BoolVar(&quiet, "q", false, "suppress informational output (shorthand)")
+	fs.StringVar(&passBackend, "pass-backend", "", "pass backend: pass, gopass, or native (default $ENVSEED_PASS_BACKEND or pass)")
+	fs.BoolVar(&useAgent, "agent", false, "consult the envseed agent for pass values (default $ENVSEED_AGENT)")
+	fs.StringVar(&ageIdentity, "age-identity", "", "age identity file for <age:...> placeholders (default $ENVSEED_AGE_IDENTITY)")
+	fs.IntVar(&jobs, "jobs", envseed.DefaultJobs, "number of placeholders resolved concurrently")
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: envseed push [flags] [INPUT_FILE]\n\nFlags:\n")
+		fs.SetOutput(os.Stderr)
+		fs.PrintDefaults()
+		fs.SetOutput(io.Discard)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.Usage()
+			return exitRequest{code: envseed.ExitOK}
+		}
+		return envseed.NewExitError("EVE-101-5", err.Error())
+	}
+
+	if fs.NArg() > 1 {
+		return envseed.NewExitError("EVE-101-6")
+	}
+
+	inputPath := ".envseed"
+	if fs.NArg() == 1 {
+		inputPath = fs.Arg(0)
+	}
+	if inputPath == "-" {
+		return envseed.NewExitError("EVE-101-101")
+	}
+	if jobs < 1 {
+		return envseed.NewExitError("EVE-101-8", jobs)
+	}
+	client, err := passClient(passBackend, useAgent)
+	if err != nil {
+		return err
+	}
+	return envseed.Push(ctx, envseed.PushOptions{
+		InputPath:   inputPath,
+		OutputPath:  outputPath,
+		Profile:     profile,
+		Yes:         yes,
+		DryRun:      dryRun,
+		Quiet:       quiet,
+		AgeIdentity: ageIdentity,
+		Jobs:        jobs,
+		PassClient:  client,
+		Stderr:      os.Stderr,
+	})
+}
+
+func runAgent(ctx context.Context, args []string) error {
+	var socket string
+	var ttl time.Duration
+	var maxUses int
+	var stop bool
+	var flush bool
+	var quiet bool
+
+	fs := flag.NewFlagSet("agent", flag.ContinueOnError)
+	fs.StringVar(&socket, "socket", "", "socket path (default $ENVSEED_AGENT_SOCK or $XDG_RUNTIME_DIR/envseed/agent.sock)")
+	fs.DurationVar(&ttl, "ttl", envseed.DefaultAgentTTL, "how long a value is kept after it is cached")
+	fs.IntVar(&maxUses, "max-uses", 0, "drop a value after this many reads (0: unlimited)")
+	fs.BoolVar(&stop, "stop", false, "stop the running agent")
+	fs.BoolVar(&flush, "flush", false, "drop every value cached by the running agent")
+	fs.BoolVar(&quiet, "quiet", false, "suppress informational output")
+	fs.BoolVar(&quiet, "q", false, "suppress informational output (shorthand)")
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: envseed agent [flags]\n\nFlags:\n")
+		fs.SetOutput(os.Stderr)
+		fs.PrintDefaults()
+		fs.SetOutput(io.Discard)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.Usage()
+			return exitRequest{code: envseed.ExitOK}
+		}
+		return envseed.NewExitError("EVE-101-5", err.Error())
+	}
+
+	if fs.NArg() > 0 {
+		return envseed.NewExitError("EVE-101-6")
+	}
+	if stop && flush {
+		return envseed.NewExitError("EVE-101-3")
+	}
+	if ttl <= 0 {
+		return envseed.NewExitError("EVE-101-13", ttl.String(), "--ttl")
+	}
+	if maxUses < 0 {
+		return envseed.NewExitError("EVE-101-13", fmt.Sprint(maxUses), "--max-uses")
+	}
+	if socket == "" {
+		socket = envseed.AgentSocketPath()
+	}
+	switch {
+	case stop:
+		if err := envseed.AgentStop(socket); err != nil {
+			return err
+		}
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "agent: stopped %s\n", socket)
+		}
+		return nil
+	case flush:
+		n, err := envseed.AgentFlush(socket)
+		if err != nil {
+			return err
+		}
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "agent: flushed %d cached values\n", n)
+		}
+		return nil
+	}
+	return envseed.RunAgent(ctx, envseed.AgentOptions{
+		Socket:  socket,
+		TTL:     ttl,
+		MaxUses: maxUses,
+		Quiet:   quiet,
+		Stderr:  os.Stderr,
+	})
+}
+
+// stringList collects the values of a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 func runVersion(args []string) error {
 	if len(args) > 0 {
 		return envseed.NewExitError("EVE-101-4")
@@ -190,6 +481,30 @@ func runVersion(args []string) error {
 	return nil
 }
 
+// passClient selects the pass backend named by the --pass-backend flag,
+// falling back to ENVSEED_PASS_BACKEND when the flag is not given. With
+// --agent, or a true ENVSEED_AGENT, the backend is consulted through the agent,
+// which is skipped when none is running.
+func passClient(backend string, agent bool) (envseed.PassClient, error) {
+	if backend == "" {
+		backend = os.Getenv(envseed.PassBackendEnv)
+	}
+	client, err := envseed.NewPassClient(backend)
+	if err != nil {
+		return nil, err
+	}
+	if !agent {
+		agent, _ = strconv.ParseBool(os.Getenv(envseed.AgentEnv))
+	}
+	if !agent {
+		return client, nil
+	}
+	if backend == "" {
+		backend = envseed.PassBackendPass
+	}
+	return envseed.NewAgentClient(client, backend+":"+os.Getenv("PASSWORD_STORE_DIR")), nil
+}
+
 func handleError(err error) {
 	if err == nil {
 		return
@@ -225,6 +540,9 @@ func printUsage(w io.Writer) {
 	fmt.Fprintln(w, "  sync      Render a template into its .env target")
 	fmt.Fprintln(w, "  diff      Compare the current .env file with regenerated output")
 	fmt.Fprintln(w, "  validate  Parse the template and report syntax errors")
+	fmt.Fprintln(w, "  import    Store the values of an existing .env in pass and write its template")
+	fmt.Fprintln(w, "  push      Write values edited in the .env back into pass")
+	fmt.Fprintln(w, "  agent     Cache resolved pass values in memory across runs")
 	fmt.Fprintln(w, "  version   Print the EnvSeed version string")
 	fmt.Fprintln(w, "\nGlobal Options:")
 	fmt.Fprintln(w, "  --version  Print the EnvSeed version string and exit")
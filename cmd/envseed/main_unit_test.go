@@ -160,3 +160,162 @@ func TestRunValidateSuccess(t *testing.T) {
 }
 
 // removed local errorAs helper; use errors.As directly
+
+// [EVT-BCU-11]
+func TestRunRejectsUnknownPassBackend(t *testing.T) {
+	t.Setenv(envseed.PassBackendEnv, "")
+	dir := t.TempDir()
+	input := filepath.Join(dir, "simple.envseed")
+	if err := os.WriteFile(input, []byte("A=1\n"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	err := runSync(context.Background(), []string{"--pass-backend", "keepass", "--dry-run", input})
+	var exitErr *envseed.ExitError
+	if !errors.As(err, &exitErr) || exitErr.DetailCode != "EVE-101-7" {
+		t.Fatalf("runSync with --pass-backend keepass = %v, want EVE-101-7", err)
+	}
+
+	t.Setenv(envseed.PassBackendEnv, "keepass")
+	err = runDiff(context.Background(), []string{input})
+	if !errors.As(err, &exitErr) || exitErr.DetailCode != "EVE-101-7" {
+		t.Fatalf("runDiff with %s=keepass = %v, want EVE-101-7", envseed.PassBackendEnv, err)
+	}
+}
+
+// [EVT-BCU-16]
+func TestPassClientUsesAgentOnlyWhenEnabled(t *testing.T) {
+	for _, tc := range []struct {
+		flag  bool
+		env   string
+		agent bool
+	}{
+		{false, "", false},
+		{false, "0", false},
+		{false, "yes", false},
+		{true, "", true},
+		{false, "1", true},
+		{false, "true", true},
+	} {
+		t.Setenv(envseed.AgentEnv, tc.env)
+		client, err := passClient("", tc.flag)
+		if err != nil {
+			t.Fatalf("passClient(%v) with %s=%q error: %v", tc.flag, envseed.AgentEnv, tc.env, err)
+		}
+		if _, ok := client.(*envseed.AgentClient); ok != tc.agent {
+			t.Fatalf("passClient(%v) with %s=%q = %T, want agent %v", tc.flag, envseed.AgentEnv, tc.env, client, tc.agent)
+		}
+	}
+}
+
+// [EVT-BCU-12]
+func TestRunRejectsInvalidJobs(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "simple.envseed")
+	if err := os.WriteFile(input, []byte("A=1\n"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	var exitErr *envseed.ExitError
+	err := runSync(context.Background(), []string{"--jobs", "0", "--dry-run", input})
+	if !errors.As(err, &exitErr) || exitErr.DetailCode != "EVE-101-8" {
+		t.Fatalf("runSync with --jobs 0 = %v, want EVE-101-8", err)
+	}
+	err = runDiff(context.Background(), []string{"--jobs", "-2", input})
+	if !errors.As(err, &exitErr) || exitErr.DetailCode != "EVE-101-8" {
+		t.Fatalf("runDiff with --jobs -2 = %v, want EVE-101-8", err)
+	}
+}
+
+// [EVT-BCU-13]
+func TestRunSyncGenerateMissingRejectsDryRun(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "simple.envseed")
+	if err := os.WriteFile(input, []byte("A=<pass:new>\n"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	var exitErr *envseed.ExitError
+	err := runSync(context.Background(), []string{"--generate-missing", "--dry-run", input})
+	if !errors.As(err, &exitErr) || exitErr.DetailCode != "EVE-101-3" {
+		t.Fatalf("runSync with --generate-missing --dry-run = %v, want EVE-101-3", err)
+	}
+}
+
+// [EVT-BCU-14]
+func TestRunImportRequiresPrefix(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".env")
+	if err := os.WriteFile(input, []byte("A=1\n"), 0o600); err != nil {
+		t.Fatalf("write .env: %v", err)
+	}
+	var exitErr *envseed.ExitError
+	err := runImport(context.Background(), []string{input})
+	if !errors.As(err, &exitErr) || exitErr.DetailCode != "EVE-101-9" {
+		t.Fatalf("runImport without --prefix = %v, want EVE-101-9", err)
+	}
+	err = runImport(context.Background(), []string{"--prefix", "app", "--keep", "[", input})
+	if !errors.As(err, &exitErr) || exitErr.DetailCode != "EVE-101-11" {
+		t.Fatalf("runImport with a bad --keep = %v, want EVE-101-11", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".envseed")); !os.IsNotExist(err) {
+		t.Fatalf("template written despite errors: %v", err)
+	}
+}
+
+// [EVT-BCU-15]
+func TestRunPushRequiresConfirmation(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "pass"), []byte("#!/bin/sh\necho old\n"), 0o755); err != nil {
+		t.Fatalf("write pass stub: %v", err)
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv(envseed.PassBackendEnv, "")
+	dir := t.TempDir()
+	input := filepath.Join(dir, "app.envseed")
+	if err := os.WriteFile(input, []byte("A=<pass:a>\n"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.env"), []byte("A=new\n"), 0o600); err != nil {
+		t.Fatalf("write .env: %v", err)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe stdin: %v", err)
+	}
+	defer r.Close()
+	_ = w.Close()
+	oldStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = oldStdin }()
+
+	var exitErr *envseed.ExitError
+	var runErr error
+	captureOutput(t, func() { runErr = runPush(context.Background(), []string{input}) })
+	if !errors.As(runErr, &exitErr) || exitErr.DetailCode != "EVE-101-12" {
+		t.Fatalf("runPush without a terminal = %v, want EVE-101-12", runErr)
+	}
+	runErr = runPush(context.Background(), []string{"--jobs", "0", input})
+	if !errors.As(runErr, &exitErr) || exitErr.DetailCode != "EVE-101-8" {
+		t.Fatalf("runPush --jobs 0 = %v, want EVE-101-8", runErr)
+	}
+}
+
+// [EVT-BCU-16]
+func TestRunAgentValidatesFlags(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "agent", "agent.sock")
+	for _, tc := range []struct {
+		args []string
+		code string
+	}{
+		{[]string{"--stop", "--flush"}, "EVE-101-3"},
+		{[]string{"--ttl", "0s"}, "EVE-101-13"},
+		{[]string{"--max-uses", "-1"}, "EVE-101-13"},
+		{[]string{"extra"}, "EVE-101-6"},
+		{[]string{"--socket", socket, "--stop"}, "EVE-104-801"},
+		{[]string{"--socket", socket, "--flush"}, "EVE-104-801"},
+	} {
+		var exitErr *envseed.ExitError
+		err := runAgent(context.Background(), tc.args)
+		if !errors.As(err, &exitErr) || exitErr.DetailCode != tc.code {
+			t.Fatalf("runAgent(%v) = %v, want %s", tc.args, err, tc.code)
+		}
+	}
+}
@@ -2,4 +2,12 @@ module envseed
 
 go 1.25.3
 
-require github.com/pmezard/go-difflib v1.0.0 // indirect
+require (
+	filippo.io/age v1.2.1
+	github.com/pmezard/go-difflib v1.0.0
+)
+
+require (
+	golang.org/x/crypto v0.24.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
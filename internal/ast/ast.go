@@ -1,5 +1,10 @@
 package ast
 
+import (
+	"strings"
+	"unicode"
+)
+
 type ElementType int
 
 const (
@@ -16,6 +21,14 @@ const (
 	ContextSingleQuoted
 	ContextCommandSubstitution
 	ContextBacktick
+	// ContextDollarSingleQuoted is ANSI-C quoting, `$'...'`.
+	ContextDollarSingleQuoted
+	// ContextParameterExpansion is the word of an unquoted `${NAME:-word}`
+	// (or any other `${...}` operator).
+	ContextParameterExpansion
+	// ContextDoubleQuotedParameterExpansion is the word of a `${...}` inside
+	// double quotes.
+	ContextDoubleQuotedParameterExpansion
 )
 
 type ValueTokenKind int
@@ -32,20 +45,109 @@ const (
 	OperatorAppend
 )
 
+// SchemePass is the scheme of `<pass:...>` placeholders.
+const SchemePass = "pass"
+
+// PluginSchemePrefix marks placeholder schemes served by external resolver
+// plugins: `<x-NAME:...>` is resolved by the `envseed-resolver-NAME` executable.
+const PluginSchemePrefix = "x-"
+
+// PluginName returns NAME for a plugin scheme `x-NAME`.
+func PluginName(scheme string) (string, bool) {
+	if len(scheme) <= len(PluginSchemePrefix) || scheme[:len(PluginSchemePrefix)] != PluginSchemePrefix {
+		return "", false
+	}
+	return scheme[len(PluginSchemePrefix):], true
+}
+
+// MaxGenLength is the largest `gen_len=N` accepted for generated secrets.
+const MaxGenLength = 4096
+
+// GenCharsets maps the names accepted by `gen_charset=NAME` to the characters
+// a generated secret is drawn from.
+var GenCharsets = map[string]string{
+	"alnum":     "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789",
+	"alpha":     "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+	"digits":    "0123456789",
+	"hex":       "0123456789abcdef",
+	"base64url": "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_",
+	"symbols":   "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~",
+}
+
+// Modifier is one placeholder modifier: a bare name such as `strip`, or a name
+// with an argument such as `field=user` or `field="user name"`.
+type Modifier struct {
+	Name string
+	// Arg is the argument with quoting and escapes removed. HasArg
+	// distinguishes `name=""` from a bare `name`.
+	Arg    string
+	HasArg bool
+	// Column is the source column of the modifier name.
+	Column int
+}
+
+// String returns the modifier in template syntax. The argument is written
+// bare when possible and double-quoted otherwise.
+func (m Modifier) String() string {
+	if !m.HasArg {
+		return m.Name
+	}
+	if IsBareModifierArg(m.Arg) {
+		return m.Name + "=" + m.Arg
+	}
+	var b strings.Builder
+	b.WriteString(m.Name)
+	b.WriteString("=\"")
+	for _, r := range m.Arg {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// IsBareModifierArg reports whether arg can be written without quotes: it is
+// non-empty and contains no whitespace, control characters, quotes, '\',
+// ',', '<', '>' or '|'.
+func IsBareModifierArg(arg string) bool {
+	if arg == "" {
+		return false
+	}
+	for _, r := range arg {
+		switch r {
+		case '"', '\'', '\\', ',', '<', '>', '|':
+			return false
+		}
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
 type ValueToken struct {
 	Kind      ValueTokenKind
 	Text      string
+	Scheme    string
 	Path      string
-	Modifiers []string
+	Modifiers []Modifier
 	Context   ValueContext
 	Line      int
 	Column    int
 }
 
 type Assignment struct {
-	Name               string
-	Operator           AssignmentOperator
-	LeadingWhitespace  string
+	Name              string
+	Operator          AssignmentOperator
+	LeadingWhitespace string
+	// Declaration is the `export`, `readonly` or `declare -x` prefix as
+	// written, including the whitespace before the name; empty when absent.
+	Declaration string
+	// File is the template the assignment was read from when it was parsed
+	// with parser.ParseFile; empty otherwise.
+	File               string
 	Raw                string
 	ValueTokens        []ValueToken
 	TrailingComment    string
@@ -0,0 +1,90 @@
+package envseed
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// AgeIdentityEnv names the environment variable holding the age identity file
+// used when no identity is configured explicitly.
+const AgeIdentityEnv = "ENVSEED_AGE_IDENTITY"
+
+// AgeClient implements SchemeClient for `<age:PATH>` placeholders. PATH names
+// an age-encrypted file (binary or ASCII-armored); relative paths are resolved
+// against BaseDir (the template's directory).
+type AgeClient struct {
+	BaseDir string
+	// IdentityFile defaults to $ENVSEED_AGE_IDENTITY.
+	IdentityFile string
+
+	mu         sync.Mutex
+	identities []age.Identity
+}
+
+// Show decrypts the file at PATH and returns its plaintext.
+func (a *AgeClient) Show(_ context.Context, path string) (string, error) {
+	identities, err := a.loadIdentities()
+	if err != nil {
+		return "", err
+	}
+	full := path
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(a.BaseDir, full)
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", NewExitError("EVE-104-203", path).WithErr(err)
+		}
+		return "", NewExitError("EVE-104-102", path).WithErr(err)
+	}
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(data, []byte(armor.Header)) {
+		src = armor.NewReader(src)
+	}
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return "", NewExitError("EVE-104-103", path).WithErr(err)
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return "", NewExitError("EVE-104-103", path).WithErr(err)
+	}
+	return string(plain), nil
+}
+
+// loadIdentities parses the identity file once per client.
+func (a *AgeClient) loadIdentities() ([]age.Identity, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.identities != nil {
+		return a.identities, nil
+	}
+	file := a.IdentityFile
+	if file == "" {
+		file = os.Getenv(AgeIdentityEnv)
+	}
+	if file == "" {
+		return nil, NewExitError("EVE-104-402")
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, NewExitError("EVE-104-403", file).WithErr(err)
+	}
+	defer f.Close()
+	identities, err := age.ParseIdentities(bufio.NewReader(f))
+	if err != nil {
+		return nil, NewExitError("EVE-104-403", file).WithErr(err)
+	}
+	a.identities = identities
+	return identities, nil
+}
@@ -0,0 +1,138 @@
+package envseed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// writeAgeFile encrypts plaintext to recipient at path, optionally armored.
+func writeAgeFile(t *testing.T, path, plaintext string, recipient age.Recipient, armored bool) {
+	t.Helper()
+	var buf bytes.Buffer
+	var dst io.Writer = &buf
+	var armorWriter io.WriteCloser
+	if armored {
+		armorWriter = armor.NewWriter(&buf)
+		dst = armorWriter
+	}
+	w, err := age.Encrypt(dst, recipient)
+	if err != nil {
+		t.Fatalf("age.Encrypt: %v", err)
+	}
+	if _, err := io.WriteString(w, plaintext); err != nil {
+		t.Fatalf("write plaintext: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close age writer: %v", err)
+	}
+	if armorWriter != nil {
+		if err := armorWriter.Close(); err != nil {
+			t.Fatalf("close armor writer: %v", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("write age file: %v", err)
+	}
+}
+
+// writeAgeIdentity generates an X25519 identity and stores it in dir.
+func writeAgeIdentity(t *testing.T, dir string) (*age.X25519Identity, string) {
+	t.Helper()
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("generate identity: %v", err)
+	}
+	path := filepath.Join(dir, "key.txt")
+	content := "# created: test\n# public key: " + id.Recipient().String() + "\n" + id.String() + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write identity: %v", err)
+	}
+	return id, path
+}
+
+// [EVT-MZU-11]
+func TestSyncResolvesAgeFiles(t *testing.T) {
+	dir := t.TempDir()
+	id, keyPath := writeAgeIdentity(t, t.TempDir())
+	writeAgeFile(t, filepath.Join(dir, "secrets", "token.age"), "tok$en\n", id.Recipient(), false)
+	writeAgeFile(t, filepath.Join(dir, "secrets", "cert.age"), "line1\nline2\n", id.Recipient(), true)
+	input := filepath.Join(dir, ".envseed")
+	template := "TOKEN=<age:secrets/token.age>\nCERT=\"<age:secrets/cert.age|allow_newline>\"\n"
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	t.Setenv(AgeIdentityEnv, keyPath)
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: &fakePass{}, Quiet: true}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if want := "TOKEN=tok\\$en\nCERT=\"line1\nline2\"\n"; string(data) != want {
+		t.Fatalf("output = %q, want %q", string(data), want)
+	}
+}
+
+// [EVT-MZU-11]
+func TestAgeClientIdentitySelection(t *testing.T) {
+	dir := t.TempDir()
+	id, keyPath := writeAgeIdentity(t, t.TempDir())
+	writeAgeFile(t, filepath.Join(dir, "a.age"), "value", id.Recipient(), false)
+
+	t.Setenv(AgeIdentityEnv, filepath.Join(dir, "does-not-exist"))
+	got, err := (&AgeClient{BaseDir: dir, IdentityFile: keyPath}).Show(context.Background(), "a.age")
+	if err != nil || got != "value" {
+		t.Fatalf("explicit identity: Show = %q, %v", got, err)
+	}
+
+	t.Setenv(AgeIdentityEnv, "")
+	_, err = (&AgeClient{BaseDir: dir}).Show(context.Background(), "a.age")
+	expectExitDetail(t, err, "EVE-104-402")
+}
+
+// [EVT-MZU-11][EVT-MZU-2]
+func TestAgeClientErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, keyPath := writeAgeIdentity(t, t.TempDir())
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("generate identity: %v", err)
+	}
+	writeAgeFile(t, filepath.Join(dir, "other.age"), "value", other.Recipient(), false)
+	if err := os.WriteFile(filepath.Join(dir, "garbage.age"), []byte("not age"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	badKey := filepath.Join(dir, "bad-key.txt")
+	if err := os.WriteFile(badKey, []byte("AGE-SECRET-KEY-NOPE\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	client := &AgeClient{BaseDir: dir, IdentityFile: keyPath}
+	for path, code := range map[string]string{
+		"other.age":   "EVE-104-103",
+		"garbage.age": "EVE-104-103",
+		"missing.age": "EVE-104-203",
+	} {
+		_, err := client.Show(context.Background(), path)
+		expectExitDetail(t, err, code)
+	}
+	_, err = (&AgeClient{BaseDir: dir, IdentityFile: badKey}).Show(context.Background(), "other.age")
+	expectExitDetail(t, err, "EVE-104-403")
+	_, err = (&AgeClient{BaseDir: dir, IdentityFile: filepath.Join(dir, "nope.txt")}).Show(context.Background(), "other.age")
+	exitErr := expectExitDetail(t, err, "EVE-104-403")
+	if !errors.Is(exitErr, os.ErrNotExist) {
+		t.Fatalf("EVE-104-403 does not wrap the open error: %v", exitErr)
+	}
+}
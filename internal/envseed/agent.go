@@ -0,0 +1,282 @@
+package envseed
+
+// Secret caching agent (`envseed agent`).
+// This file holds:
+//  - AgentSocketPath: where the agent listens
+//  - RunAgent: serves resolved `pass` values from memory with a TTL and a
+//    max-uses limit until stopped
+//  - agentRequest / agentResponse: the versioned wire format, one JSON
+//    request and one JSON response per connection
+//  - agentCache: the in-memory store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AgentSocketEnv names the environment variable holding the agent socket
+// path; it overrides the default of AgentSocketPath.
+const AgentSocketEnv = "ENVSEED_AGENT_SOCK"
+
+// AgentEnv names the environment variable that makes commands consult the
+// agent when set to a true value such as `1`, as the `--agent` flag does.
+const AgentEnv = "ENVSEED_AGENT"
+
+// AgentProtocolVersion is sent in every agent request and response.
+const AgentProtocolVersion = 1
+
+// DefaultAgentTTL is how long the agent keeps a value when no TTL is given.
+const DefaultAgentTTL = 15 * time.Minute
+
+// agentMessageLimit bounds a single request or response on the socket.
+const agentMessageLimit = 4 << 20
+
+// Agent operations.
+const (
+	agentOpGet   = "get"
+	agentOpPut   = "put"
+	agentOpFlush = "flush"
+	agentOpStop  = "stop"
+)
+
+type agentRequest struct {
+	Version int    `json:"version"`
+	Op      string `json:"op"`
+	Key     string `json:"key,omitempty"`
+	Value   string `json:"value,omitempty"`
+}
+
+type agentResponse struct {
+	Version int     `json:"version"`
+	Value   *string `json:"value,omitempty"`
+	Count   int     `json:"count,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// AgentSocketPath returns the agent socket: $ENVSEED_AGENT_SOCK, else
+// `envseed/agent.sock` below $XDG_RUNTIME_DIR, else below a per-user folder
+// of the temporary directory.
+func AgentSocketPath() string {
+	if path := os.Getenv(AgentSocketEnv); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "envseed", "agent.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("envseed-%d", os.Getuid()), "agent.sock")
+}
+
+// RunAgent listens on opts.Socket and serves the cache until ctx is done or a
+// stop request arrives; the socket is removed on return. The socket folder is
+// created with mode 0700 and must not be accessible by other users; the
+// socket itself has mode 0600.
+func RunAgent(ctx context.Context, opts AgentOptions) error {
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	socket := opts.Socket
+	if socket == "" {
+		socket = AgentSocketPath()
+	}
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = DefaultAgentTTL
+	}
+
+	dir := filepath.Dir(socket)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return NewExitError("EVE-104-803", socket).WithErr(err)
+	}
+	if err := checkAgentDir(dir); err != nil {
+		return NewExitError("EVE-104-803", socket).WithErr(err)
+	}
+	if conn, err := net.Dial("unix", socket); err == nil {
+		_ = conn.Close()
+		return NewExitError("EVE-104-802", socket)
+	}
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return NewExitError("EVE-104-803", socket).WithErr(err)
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return NewExitError("EVE-104-803", socket).WithErr(err)
+	}
+	defer os.Remove(socket)
+	if err := os.Chmod(socket, 0o600); err != nil {
+		_ = listener.Close()
+		return NewExitError("EVE-104-803", socket).WithErr(err)
+	}
+
+	cache := newAgentCache(ttl, opts.MaxUses)
+	defer cache.flush()
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+	go cache.expireEvery(ctx, min(ttl, time.Minute))
+
+	if !opts.Quiet {
+		uses := "unlimited"
+		if opts.MaxUses > 0 {
+			uses = fmt.Sprint(opts.MaxUses)
+		}
+		fmt.Fprintf(stderr, "agent: listening on %s (ttl %s, max uses %s)\n", socket, ttl, uses)
+	}
+	var wg sync.WaitGroup
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			if serveAgentConn(conn, cache) {
+				stop()
+			}
+		}()
+	}
+	wg.Wait()
+	if !opts.Quiet {
+		fmt.Fprintln(stderr, "agent: stopped")
+	}
+	return nil
+}
+
+// serveAgentConn answers one request and reports whether it asked the agent
+// to stop.
+func serveAgentConn(conn net.Conn, cache *agentCache) bool {
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	var req agentRequest
+	resp := agentResponse{Version: AgentProtocolVersion}
+	line, err := bufio.NewReader(io.LimitReader(conn, agentMessageLimit)).ReadBytes('\n')
+	switch {
+	case err != nil:
+		resp.Error = "incomplete request"
+	case json.Unmarshal(line, &req) != nil:
+		resp.Error = "malformed request"
+	case req.Version != AgentProtocolVersion:
+		resp.Error = fmt.Sprintf("unsupported protocol version %d", req.Version)
+	}
+	if resp.Error == "" {
+		switch req.Op {
+		case agentOpGet:
+			if value, ok := cache.get(req.Key); ok {
+				resp.Value = &value
+			}
+		case agentOpPut:
+			cache.put(req.Key, req.Value)
+		case agentOpFlush:
+			resp.Count = cache.flush()
+		case agentOpStop:
+		default:
+			resp.Error = fmt.Sprintf("unknown op %q", req.Op)
+		}
+	}
+	data, _ := json.Marshal(resp)
+	_, _ = conn.Write(append(data, '\n'))
+	return resp.Error == "" && req.Op == agentOpStop
+}
+
+// agentCache holds values until they expire or reach the use limit. Values
+// are kept as bytes so that they can be overwritten when dropped.
+type agentCache struct {
+	ttl     time.Duration
+	maxUses int
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*agentEntry
+}
+
+type agentEntry struct {
+	value   []byte
+	expires time.Time
+	uses    int
+}
+
+func newAgentCache(ttl time.Duration, maxUses int) *agentCache {
+	return &agentCache{ttl: ttl, maxUses: maxUses, now: time.Now, entries: make(map[string]*agentEntry)}
+}
+
+// get returns the value of key and counts a use; the entry is dropped once
+// it reaches the use limit.
+func (c *agentCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !c.now().Before(e.expires) {
+		c.drop(key)
+		return "", false
+	}
+	value := string(e.value)
+	e.uses++
+	if c.maxUses > 0 && e.uses >= c.maxUses {
+		c.drop(key)
+	}
+	return value, true
+}
+
+// put stores value for key with a fresh TTL and use count.
+func (c *agentCache) put(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drop(key)
+	c.entries[key] = &agentEntry{value: []byte(value), expires: c.now().Add(c.ttl)}
+}
+
+// flush drops every entry and returns how many there were.
+func (c *agentCache) flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := len(c.entries)
+	for key := range c.entries {
+		c.drop(key)
+	}
+	return n
+}
+
+// expireEvery drops expired entries every interval until ctx is done.
+func (c *agentCache) expireEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			now := c.now()
+			for key, e := range c.entries {
+				if !now.Before(e.expires) {
+					c.drop(key)
+				}
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// drop overwrites and removes the entry of key; c.mu must be held.
+func (c *agentCache) drop(key string) {
+	if e, ok := c.entries[key]; ok {
+		clear(e.value)
+		delete(c.entries, key)
+	}
+}
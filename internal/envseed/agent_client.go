@@ -0,0 +1,242 @@
+package envseed
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// errAgentUnavailable reports that no agent answers on the socket.
+var errAgentUnavailable = errors.New("agent not running")
+
+// AgentClient implements PassClient by consulting the agent before Next:
+// values the agent holds are served without decryption, and values read from
+// Next are handed to the agent. When no agent is running, or it fails, every
+// call goes to Next. Keys are prefixed with Namespace, which identifies the
+// store, so that agents shared by several stores never mix their entries.
+type AgentClient struct {
+	Socket    string
+	Namespace string
+	Next      PassClient
+
+	mu          sync.Mutex
+	unavailable bool
+	prefetched  map[string]string
+}
+
+// NewAgentClient wraps next for the agent at AgentSocketPath. namespace
+// identifies the backend and store next reads from.
+func NewAgentClient(next PassClient, namespace string) *AgentClient {
+	return &AgentClient{Socket: AgentSocketPath(), Namespace: namespace, Next: next}
+}
+
+// Show returns PATH from the agent, or from Next and then stores it in the
+// agent.
+func (a *AgentClient) Show(ctx context.Context, path string) (string, error) {
+	a.mu.Lock()
+	value, ok := a.prefetched[path]
+	a.mu.Unlock()
+	if ok {
+		return value, nil
+	}
+	if value, ok := a.get(path); ok {
+		return value, nil
+	}
+	value, err := a.Next.Show(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	a.put(path, value)
+	return value, nil
+}
+
+// Prefetch asks the agent for every PATH first and passes the ones it does
+// not hold to Next when Next is a Prefetcher.
+func (a *AgentClient) Prefetch(ctx context.Context, paths []string) {
+	var missing []string
+	for _, path := range paths {
+		value, ok := a.get(path)
+		if !ok {
+			missing = append(missing, path)
+			continue
+		}
+		a.mu.Lock()
+		if a.prefetched == nil {
+			a.prefetched = make(map[string]string)
+		}
+		a.prefetched[path] = value
+		a.mu.Unlock()
+	}
+	if p, ok := a.Next.(Prefetcher); ok && len(missing) > 0 {
+		p.Prefetch(ctx, missing)
+	}
+}
+
+// Insert creates PATH through Next and caches the new value.
+func (a *AgentClient) Insert(ctx context.Context, path, value string) error {
+	inserter, ok := a.Next.(Inserter)
+	if !ok {
+		return NewExitError("EVE-104-701", "pass")
+	}
+	if err := inserter.Insert(ctx, path, value); err != nil {
+		return err
+	}
+	a.forget(path)
+	a.put(path, value)
+	return nil
+}
+
+// Update replaces PATH through Next and caches the new value.
+func (a *AgentClient) Update(ctx context.Context, path, value string) error {
+	updater, ok := a.Next.(Updater)
+	if !ok {
+		return NewExitError("EVE-104-706", "pass")
+	}
+	if err := updater.Update(ctx, path, value); err != nil {
+		return err
+	}
+	a.forget(path)
+	a.put(path, value)
+	return nil
+}
+
+// Close drops the values Prefetch received from the agent. The client can
+// still be used; later calls consult the agent again.
+func (a *AgentClient) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for path := range a.prefetched {
+		delete(a.prefetched, path)
+	}
+	a.prefetched = nil
+}
+
+func (a *AgentClient) get(path string) (string, bool) {
+	resp, err := a.call(agentRequest{Op: agentOpGet, Key: a.key(path)})
+	if err != nil || resp.Value == nil {
+		return "", false
+	}
+	return *resp.Value, true
+}
+
+func (a *AgentClient) put(path, value string) {
+	_, _ = a.call(agentRequest{Op: agentOpPut, Key: a.key(path), Value: value})
+}
+
+func (a *AgentClient) forget(path string) {
+	a.mu.Lock()
+	delete(a.prefetched, path)
+	a.mu.Unlock()
+}
+
+func (a *AgentClient) key(path string) string {
+	return a.Namespace + "\x00" + path
+}
+
+// call sends req unless an earlier call found no usable agent.
+func (a *AgentClient) call(req agentRequest) (agentResponse, error) {
+	a.mu.Lock()
+	unavailable := a.unavailable
+	a.mu.Unlock()
+	if unavailable {
+		return agentResponse{}, errAgentUnavailable
+	}
+	resp, err := agentCall(a.Socket, req)
+	if err != nil {
+		a.mu.Lock()
+		a.unavailable = true
+		a.mu.Unlock()
+	}
+	return resp, err
+}
+
+// AgentStop asks the agent at socket to stop.
+func AgentStop(socket string) error {
+	if _, err := agentCommand(socket, agentOpStop); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AgentFlush asks the agent at socket to drop every value and returns how
+// many it held.
+func AgentFlush(socket string) (int, error) {
+	resp, err := agentCommand(socket, agentOpFlush)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Count, nil
+}
+
+// agentCommand sends op and classifies failures for the agent subcommand.
+func agentCommand(socket, op string) (agentResponse, error) {
+	resp, err := agentCall(socket, agentRequest{Op: op})
+	switch {
+	case errors.Is(err, errAgentUnavailable):
+		return resp, NewExitError("EVE-104-801", socket).WithErr(err)
+	case err != nil:
+		return resp, NewExitError("EVE-104-804", socket).WithErr(err)
+	}
+	return resp, nil
+}
+
+// agentCall sends one request to the agent at socket and reads its response.
+// The socket folder must belong to the current user and be closed to others;
+// otherwise, or when nothing listens, errAgentUnavailable is returned.
+func agentCall(socket string, req agentRequest) (agentResponse, error) {
+	if err := checkAgentDir(filepath.Dir(socket)); err != nil {
+		return agentResponse{}, fmt.Errorf("%w: %v", errAgentUnavailable, err)
+	}
+	conn, err := net.DialTimeout("unix", socket, time.Second)
+	if err != nil {
+		return agentResponse{}, fmt.Errorf("%w: %v", errAgentUnavailable, err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	req.Version = AgentProtocolVersion
+	data, err := json.Marshal(req)
+	if err != nil {
+		return agentResponse{}, err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return agentResponse{}, err
+	}
+	line, err := bufio.NewReader(io.LimitReader(conn, agentMessageLimit)).ReadBytes('\n')
+	if err != nil {
+		return agentResponse{}, err
+	}
+	var resp agentResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return agentResponse{}, err
+	}
+	if resp.Version != AgentProtocolVersion {
+		return agentResponse{}, fmt.Errorf("unsupported protocol version %d", resp.Version)
+	}
+	if resp.Error != "" {
+		return agentResponse{}, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// errAgentDirShared reports a socket folder other users can access.
+var errAgentDirShared = errors.New("socket folder is accessible by other users")
+
+// checkAgentDirMode rejects a socket folder that is not a directory or grants
+// any permission to group or others.
+func checkAgentDirMode(info os.FileInfo) error {
+	if !info.IsDir() {
+		return errors.New("socket folder is not a directory")
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return errAgentDirShared
+	}
+	return nil
+}
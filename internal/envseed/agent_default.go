@@ -0,0 +1,15 @@
+//go:build !unix && !darwin
+
+package envseed
+
+import "os"
+
+// checkAgentDir verifies that the agent socket folder is a directory closed
+// to group and others; ownership is not checked on non-Unix builds.
+func checkAgentDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	return checkAgentDirMode(info)
+}
@@ -0,0 +1,243 @@
+package envseed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"envseed/internal/parser"
+	"envseed/internal/testsupport"
+)
+
+// startAgent runs an agent on a socket in a new private folder and waits
+// until it accepts connections. The agent is stopped when the test ends.
+func startAgent(t *testing.T, opts AgentOptions) (string, <-chan error) {
+	t.Helper()
+	opts.Socket = filepath.Join(t.TempDir(), "agent", "agent.sock")
+	opts.Quiet = true
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- RunAgent(ctx, opts)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if conn, err := net.Dial("unix", opts.Socket); err == nil {
+			_ = conn.Close()
+			return opts.Socket, done
+		}
+	}
+	t.Fatalf("agent did not start on %s", opts.Socket)
+	return "", nil
+}
+
+// prefetchPass records the paths passed to Prefetch.
+type prefetchPass struct {
+	fakePass
+	prefetched []string
+}
+
+func (p *prefetchPass) Prefetch(_ context.Context, paths []string) {
+	p.prefetched = append(p.prefetched, paths...)
+}
+
+// [EVT-MZU-23]
+func TestAgentCachesValuesAcrossRuns(t *testing.T) {
+	socket, _ := startAgent(t, AgentOptions{})
+	next := &fakePass{values: map[string]string{"db": "s3cret\n", "api": "k3y\n"}}
+	for run := 0; run < 3; run++ {
+		client := &AgentClient{Socket: socket, Namespace: "pass:", Next: next}
+		if got, err := client.Show(context.Background(), "db"); err != nil || got != "s3cret\n" {
+			t.Fatalf("run %d: Show = %q, %v", run, got, err)
+		}
+	}
+	if next.calls["db"] != 1 {
+		t.Fatalf("backend calls = %d, want 1", next.calls["db"])
+	}
+	other := &AgentClient{Socket: socket, Namespace: "pass:/other/store", Next: next}
+	if _, err := other.Show(context.Background(), "db"); err != nil || next.calls["db"] != 2 {
+		t.Fatalf("other namespace: err = %v, calls = %d; want a backend call", err, next.calls["db"])
+	}
+
+	batch := &prefetchPass{fakePass: fakePass{values: map[string]string{"api": "k3y\n"}}}
+	client := &AgentClient{Socket: socket, Namespace: "pass:", Next: batch}
+	client.Prefetch(context.Background(), []string{"db", "api"})
+	if !testsupport.EqualStrings(batch.prefetched, []string{"api"}) {
+		t.Fatalf("backend prefetched %v, want only the uncached path", batch.prefetched)
+	}
+	if got, err := client.Show(context.Background(), "db"); err != nil || got != "s3cret\n" || batch.calls["db"] != 0 {
+		t.Fatalf("Show after Prefetch = %q, %v; backend calls = %v", got, err, batch.calls)
+	}
+
+	missing := &fakePass{errs: map[string]error{"gone": NewExitError("EVE-104-201", "pass", "gone")}}
+	for run := 0; run < 2; run++ {
+		client := &AgentClient{Socket: socket, Namespace: "pass:", Next: missing}
+		if _, err := client.Show(context.Background(), "gone"); err == nil {
+			t.Fatalf("missing entry resolved from the agent")
+		}
+	}
+	if missing.calls["gone"] != 2 {
+		t.Fatalf("failures were cached: calls = %d", missing.calls["gone"])
+	}
+}
+
+// [EVT-MZU-23]
+func TestAgentClientCloseDropsPrefetchedValues(t *testing.T) {
+	socket, _ := startAgent(t, AgentOptions{})
+	next := &fakePass{values: map[string]string{"db": "s3cret\n"}}
+	seed := &AgentClient{Socket: socket, Namespace: "pass:", Next: next}
+	if _, err := seed.Show(context.Background(), "db"); err != nil {
+		t.Fatalf("Show error: %v", err)
+	}
+
+	client := &AgentClient{Socket: socket, Namespace: "pass:", Next: next}
+	resolver := newSecretResolver(context.Background(), map[string]SchemeClient{"pass": client})
+	elems, err := parser.Parse("DB=<pass:db>\n")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	resolver.Prefetch(elems, 1)
+	if len(client.prefetched) != 1 {
+		t.Fatalf("prefetched = %d values, want 1", len(client.prefetched))
+	}
+	resolver.Close()
+	if client.prefetched != nil {
+		t.Fatalf("prefetched values kept after Close: %d", len(client.prefetched))
+	}
+	if got, err := client.Show(context.Background(), "db"); err != nil || got != "s3cret\n" || next.calls["db"] != 1 {
+		t.Fatalf("Show after Close = %q, %v; backend calls = %d", got, err, next.calls["db"])
+	}
+}
+
+// [EVT-MZU-23]
+func TestAgentClientWritesThrough(t *testing.T) {
+	socket, _ := startAgent(t, AgentOptions{})
+	store := &storePass{values: map[string]string{"db": "old\n"}}
+	client := &AgentClient{Socket: socket, Namespace: "pass:", Next: store}
+	if _, err := client.Show(context.Background(), "db"); err != nil {
+		t.Fatalf("Show error: %v", err)
+	}
+	if err := client.Update(context.Background(), "db", "n3w\n"); err != nil {
+		t.Fatalf("Update error: %v", err)
+	}
+	if err := client.Insert(context.Background(), "jwt", "g3n\n"); err != nil {
+		t.Fatalf("Insert error: %v", err)
+	}
+	offline := &fakePass{errs: map[string]error{"db": errors.New("backend unavailable"), "jwt": errors.New("backend unavailable")}}
+	next := &AgentClient{Socket: socket, Namespace: "pass:", Next: offline}
+	for path, want := range map[string]string{"db": "n3w\n", "jwt": "g3n\n"} {
+		if got, err := next.Show(context.Background(), path); err != nil || got != want {
+			t.Fatalf("Show(%s) from the agent = %q, %v; want %q", path, got, err, want)
+		}
+	}
+
+	expectExitDetail(t, (&AgentClient{Socket: socket, Next: &fakePass{}}).Insert(context.Background(), "x", "y\n"), "EVE-104-701")
+	expectExitDetail(t, (&AgentClient{Socket: socket, Next: &fakePass{}}).Update(context.Background(), "x", "y\n"), "EVE-104-706")
+}
+
+// [EVT-MZU-23]
+func TestAgentCacheLimits(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := newAgentCache(time.Minute, 2)
+	c.now = func() time.Time { return now }
+	c.put("a", "1")
+	c.put("b", "2")
+	if v, ok := c.get("a"); !ok || v != "1" {
+		t.Fatalf("get(a) = %q, %v", v, ok)
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Fatalf("second use of a was refused")
+	}
+	if _, ok := c.get("a"); ok {
+		t.Fatalf("a served beyond --max-uses 2")
+	}
+	now = now.Add(time.Minute)
+	if _, ok := c.get("b"); ok {
+		t.Fatalf("b served after its TTL")
+	}
+	c.put("c", "3")
+	c.put("d", "4")
+	if n := c.flush(); n != 2 {
+		t.Fatalf("flush() = %d, want 2", n)
+	}
+	if _, ok := c.get("c"); ok {
+		t.Fatalf("c served after flush")
+	}
+
+	unlimited := newAgentCache(time.Minute, 0)
+	unlimited.put("a", "1")
+	for i := 0; i < 100; i++ {
+		if _, ok := unlimited.get("a"); !ok {
+			t.Fatalf("use %d refused without --max-uses", i+1)
+		}
+	}
+}
+
+// [EVT-MZU-23]
+func TestAgentCommands(t *testing.T) {
+	socket, done := startAgent(t, AgentOptions{})
+	client := &AgentClient{Socket: socket, Next: &fakePass{values: map[string]string{"a": "1", "b": "2"}}}
+	for _, path := range []string{"a", "b"} {
+		if _, err := client.Show(context.Background(), path); err != nil {
+			t.Fatalf("Show(%s) error: %v", path, err)
+		}
+	}
+	if n, err := AgentFlush(socket); err != nil || n != 2 {
+		t.Fatalf("AgentFlush() = %d, %v; want 2", n, err)
+	}
+	err := RunAgent(context.Background(), AgentOptions{Socket: socket, Quiet: true})
+	expectExitDetail(t, err, "EVE-104-802")
+
+	if err := AgentStop(socket); err != nil {
+		t.Fatalf("AgentStop() error = %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("RunAgent() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("agent did not stop")
+	}
+	if _, err := os.Lstat(socket); !os.IsNotExist(err) {
+		t.Fatalf("socket left behind: %v", err)
+	}
+	_, err = AgentFlush(socket)
+	expectExitDetail(t, err, "EVE-104-801")
+	expectExitDetail(t, AgentStop(socket), "EVE-104-801")
+}
+
+// [EVT-MZU-23]
+func TestAgentRequiresPrivateSocketFolder(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "shared")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	socket := filepath.Join(dir, "agent.sock")
+	var stderr bytes.Buffer
+	err := RunAgent(context.Background(), AgentOptions{Socket: socket, Stderr: &stderr})
+	expectExitDetail(t, err, "EVE-104-803")
+
+	// A listener in a shared folder is never consulted.
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	next := &fakePass{values: map[string]string{"db": "s3cret\n"}}
+	client := &AgentClient{Socket: socket, Next: next}
+	if got, err := client.Show(context.Background(), "db"); err != nil || got != "s3cret\n" || next.calls["db"] != 1 {
+		t.Fatalf("Show = %q, %v; backend calls = %d", got, err, next.calls["db"])
+	}
+	_, err = AgentFlush(socket)
+	expectExitDetail(t, err, "EVE-104-801")
+}
@@ -0,0 +1,25 @@
+//go:build unix || darwin
+
+package envseed
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// checkAgentDir verifies that the agent socket folder is a directory owned by
+// the current user and closed to group and others.
+func checkAgentDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if err := checkAgentDirMode(info); err != nil {
+		return err
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Getuid() {
+		return errors.New("socket folder is owned by another user")
+	}
+	return nil
+}
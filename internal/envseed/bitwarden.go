@@ -0,0 +1,92 @@
+package envseed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// bitwardenObjects lists the `bw get` objects a `<bw:ITEM#OBJECT>` placeholder
+// may select. Without a recognized `#OBJECT` suffix the item's password is
+// read, so item names containing '#' keep working.
+var bitwardenObjects = map[string]struct{}{
+	"password": {},
+	"username": {},
+	"notes":    {},
+	"uri":      {},
+	"totp":     {},
+}
+
+// BitwardenCommand implements SchemeClient for `<bw:ITEM>` and
+// `<bw:ITEM#OBJECT>` placeholders using the Bitwarden CLI. The vault must be
+// unlocked beforehand (`BW_SESSION`); bw never prompts during a run.
+type BitwardenCommand struct{}
+
+// Show retrieves OBJECT (default password) of ITEM with `bw get`.
+func (b *BitwardenCommand) Show(ctx context.Context, path string) (string, error) {
+	item, object := splitBitwardenPath(path)
+	cmd := exec.CommandContext(ctx, "bw", "get", object, item, "--nointeraction")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", NewExitError("EVE-104-1", "bw").WithErr(err)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			errMsg := stderr.String()
+			if msg := strings.TrimSpace(errMsg); msg != "" {
+				err = errors.New(msg)
+			}
+			switch {
+			case bwSessionExpired(errMsg):
+				return "", NewExitError("EVE-104-503", "bw").WithErr(err)
+			case bwVaultLocked(errMsg):
+				return "", NewExitError("EVE-104-502", "bw").WithErr(err)
+			case bwEntryNotFound(errMsg):
+				return "", NewExitError("EVE-104-201", "bw", path).WithErr(err)
+			}
+		}
+		return "", NewExitError("EVE-104-106", "bw", path).WithErr(err)
+	}
+	return string(out), nil
+}
+
+// splitBitwardenPath splits `ITEM#OBJECT` at the last '#' when OBJECT is a
+// known `bw get` object; otherwise PATH names the item and its password is read.
+func splitBitwardenPath(path string) (item, object string) {
+	if i := strings.LastIndexByte(path, '#'); i >= 0 {
+		if _, ok := bitwardenObjects[path[i+1:]]; ok {
+			return path[:i], path[i+1:]
+		}
+	}
+	return path, "password"
+}
+
+// bwSessionExpired recognizes bw diagnostics for a missing login or an
+// invalid `BW_SESSION` key.
+func bwSessionExpired(msg string) bool {
+	return containsAnyFold(msg,
+		"you are not logged in",
+		"session key is invalid",
+		"session has expired",
+		"invalid session",
+	)
+}
+
+// bwVaultLocked recognizes bw diagnostics for a vault that has not been
+// unlocked in this session.
+func bwVaultLocked(msg string) bool {
+	return containsAnyFold(msg, "vault is locked")
+}
+
+// bwEntryNotFound recognizes bw diagnostics for a missing item or object.
+func bwEntryNotFound(msg string) bool {
+	return containsAnyFold(msg,
+		"not found",
+		"no totp available",
+	)
+}
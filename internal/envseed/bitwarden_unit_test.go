@@ -0,0 +1,90 @@
+package envseed
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// stubBw answers `bw get OBJECT ITEM --nointeraction` for a single item named
+// "github" and logs its arguments.
+const stubBw = `#!/bin/sh
+echo "$*" >> "$STUB_BW_LOG"
+case "$3" in
+  github|'team#github') ;;
+  *) echo "Not found." >&2; exit 1 ;;
+esac
+case "$2" in
+  password) printf 's3cret' ;;
+  username) printf 'alice' ;;
+  *) echo "Not found." >&2; exit 1 ;;
+esac
+`
+
+// [EVT-MZU-14][EVT-MZU-4]
+func TestSyncResolvesBitwardenObjects(t *testing.T) {
+	installCommandStub(t, "bw", stubBw)
+	log := filepath.Join(t.TempDir(), "bw.log")
+	t.Setenv("STUB_BW_LOG", log)
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	template := "PASS=<bw:github>\nUSER=<bw:github#username>\nHASH=<bw:team#github>\n"
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: &fakePass{}, Quiet: true}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if want := "PASS=s3cret\nUSER=alice\nHASH=s3cret\n"; string(data) != want {
+		t.Fatalf("output = %q, want %q", string(data), want)
+	}
+	logData, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	runs := strings.Split(strings.TrimSpace(string(logData)), "\n")
+	sort.Strings(runs)
+	want := []string{"get password github --nointeraction", "get password team#github --nointeraction", "get username github --nointeraction"}
+	if strings.Join(runs, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("bw runs = %q, want %q", runs, want)
+	}
+}
+
+// [EVT-MZU-14][EVT-MZU-2]
+func TestBitwardenCommandClassifiesStderr(t *testing.T) {
+	cases := []struct {
+		name   string
+		stderr string
+		want   string
+	}{
+		{"not found", "Not found.", "EVE-104-201"},
+		{"locked", "Vault is locked.", "EVE-104-502"},
+		{"not logged in", "You are not logged in.", "EVE-104-503"},
+		{"invalid session", "The session key is invalid.", "EVE-104-503"},
+		{"ambiguous", "More than one result was found. Try getting a specific object by `id` instead.", "EVE-104-106"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			installCommandStub(t, "bw", "#!/bin/sh\necho '"+tc.stderr+"' >&2\nexit 1\n")
+			_, err := (&BitwardenCommand{}).Show(context.Background(), "github")
+			exitErr := expectExitDetail(t, err, tc.want)
+			if !strings.Contains(exitErr.Error(), tc.stderr) {
+				t.Fatalf("error does not carry the bw message: %v", exitErr)
+			}
+		})
+	}
+}
+
+// [EVT-MZU-14]
+func TestBitwardenCommandMissingBinary(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	_, err := (&BitwardenCommand{}).Show(context.Background(), "github")
+	expectExitDetail(t, err, "EVE-104-1")
+}
@@ -0,0 +1,56 @@
+package envseed
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// [EVT-MPU-14]
+func TestSyncDecodesBase64Entries(t *testing.T) {
+	pass := &fakePass{values: map[string]string{
+		"tls/cert": "LS0tLS1CRUdJTiBDRVJULS0tLS0K\nTUlJQgotLS0tLUVORCBDRVJULS0tLS0K",
+		"app/key":  "YQBi",
+	}}
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	template := "CERT=\"<pass:tls/cert|base64_decode,strip_right,allow_newline>\"\nKEY=<pass:app/key|base64_decode,hex>\n"
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: pass, Quiet: true}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	want := "CERT=\"-----BEGIN CERT-----\nMIIB\n-----END CERT-----\"\nKEY=610062\n"
+	if string(data) != want {
+		t.Fatalf("output = %q, want %q", string(data), want)
+	}
+}
+
+// [EVT-MPU-14][EVT-MZU-2]
+func TestSyncRejectsUndecodableEntries(t *testing.T) {
+	for template, code := range map[string]string{
+		"KEY=<pass:app/key|base64_decode>\n":    "EVE-105-902",
+		"KEY=<pass:app/broken|base64_decode>\n": "EVE-105-901",
+	} {
+		pass := &fakePass{values: map[string]string{"app/key": "YQBi", "app/broken": "not base64!"}}
+		dir := t.TempDir()
+		input := filepath.Join(dir, ".envseed")
+		if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+			t.Fatalf("write template: %v", err)
+		}
+		err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: pass, Quiet: true})
+		exitErr := expectExitDetail(t, err, code)
+		if exitErr.Code != ExitRenderError {
+			t.Fatalf("%s: exit code = %d, want %d", code, exitErr.Code, ExitRenderError)
+		}
+		if _, statErr := os.Stat(filepath.Join(dir, ".env")); !os.IsNotExist(statErr) {
+			t.Fatalf("%s: output written despite render failure", code)
+		}
+	}
+}
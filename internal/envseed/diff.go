@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"path/filepath"
 
 	"envseed/internal/parser"
 	"envseed/internal/renderer"
@@ -19,6 +20,9 @@ func Diff(ctx context.Context, opts DiffOptions) (DiffResult, error) {
 		// CLI handles input selection; treat empty as internal misuse
 		return DiffResult{}, NewExitError("EVE-102-203", "<empty>")
 	}
+	if err := checkProfileName(opts.Profile); err != nil {
+		return DiffResult{}, err
+	}
 
 	passClient := opts.PassClient
 	if passClient == nil {
@@ -29,6 +33,10 @@ func Diff(ctx context.Context, opts DiffOptions) (DiffResult, error) {
 	if stdout == nil {
 		stdout = os.Stdout
 	}
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
 
 	if info, statErr := os.Lstat(opts.InputPath); statErr != nil {
 		code := classifyStatDetail(statErr)
@@ -49,19 +57,28 @@ func Diff(ctx context.Context, opts DiffOptions) (DiffResult, error) {
 		return DiffResult{}, NewExitError("EVE-102-202", opts.InputPath).WithErr(rerr)
 	}
 
-	targetPath, err := resolveOutputPath(opts.InputPath, opts.OutputPath)
+	targetPath, err := resolveOutputPath(opts.InputPath, opts.OutputPath, opts.Profile)
 	if err != nil {
 		return DiffResult{}, err
 	}
 
 	source := string(data)
-	elements, err := parser.Parse(source)
+	elements, err := parser.ParseFile(opts.InputPath, source)
 	if err != nil {
 		return DiffResult{}, wrapParseError(err)
 	}
+	elements, err = applyProfile(elements, opts.Profile)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	elements = rebaseIncludedPaths(elements, filepath.Dir(opts.InputPath))
 
-	resolver := newPassResolver(ctx, passClient)
+	resolver := newSecretResolver(ctx, schemeClients(passClient, schemeConfig{
+		baseDir:     filepath.Dir(opts.InputPath),
+		ageIdentity: opts.AgeIdentity,
+	}, opts.Schemes))
 	defer resolver.Close()
+	resolver.Prefetch(elements, jobCount(opts.Jobs))
 
 	rendered, err := renderer.RenderElements(elements, resolver)
 	if err != nil {
@@ -84,6 +101,12 @@ func Diff(ctx context.Context, opts DiffOptions) (DiffResult, error) {
 		return DiffResult{}, NewExitError("EVE-108-1", targetPath)
 	}
 
+	// Warn about fallbacks even when the file is up to date: it then holds
+	// fallback values too.
+	if !opts.Quiet {
+		_ = writeFallbacks(stderr, "warning: ", resolver.Fallbacks())
+	}
+
 	if bytes.Equal(existing, renderedBytes) {
 		return DiffResult{Changed: false}, nil
 	}
@@ -108,6 +131,9 @@ func Diff(ctx context.Context, opts DiffOptions) (DiffResult, error) {
 			return DiffResult{}, NewExitError("EVE-108-3", targetPath).WithErr(err)
 		}
 	}
+	if err := writeFallbacks(stdout, "fallback: ", resolver.Fallbacks()); err != nil {
+		return DiffResult{}, NewExitError("EVE-108-3", targetPath).WithErr(err)
+	}
 
 	return DiffResult{Changed: true}, nil
 }
@@ -0,0 +1,97 @@
+package envseed
+
+// Document-backed schemes (`<scheme:SOURCE#key.path>`).
+// This file holds:
+//  - DocumentClient: optional interface for clients that load whole documents
+//  - splitDocumentPath / selectDocumentValue: PATH parsing and leaf selection
+//  - showDocumentValue: uncached Show helper for DocumentClient implementations
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// DocumentClient is implemented by scheme clients whose PATH selects a leaf
+// inside a structured document, written `SOURCE#key.path`. secretCache loads
+// each SOURCE once per run and selects leaves from the cached tree. Documents
+// are trees of map[string]any, []any, string, json.Number, bool and nil.
+type DocumentClient interface {
+	SchemeClient
+	LoadDocument(ctx context.Context, source string) (any, error)
+}
+
+// splitDocumentPath splits PATH at its last '#' into the document source and
+// the dotted key path.
+func splitDocumentPath(path string) (source, selector string, ok bool) {
+	i := strings.LastIndexByte(path, '#')
+	if i < 0 {
+		return path, "", false
+	}
+	return path[:i], path[i+1:], true
+}
+
+// selectDocumentValue walks doc along the dotted selector. Numeric segments
+// index into lists. found is false when a segment does not exist; scalar is
+// false when the selected node is a map or list.
+func selectDocumentValue(doc any, selector string) (value string, found, scalar bool) {
+	node := doc
+	for _, seg := range strings.Split(selector, ".") {
+		switch n := node.(type) {
+		case map[string]any:
+			next, ok := n[seg]
+			if !ok {
+				return "", false, false
+			}
+			node = next
+		case []any:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(n) {
+				return "", false, false
+			}
+			node = n[idx]
+		default:
+			return "", false, false
+		}
+	}
+	switch v := node.(type) {
+	case string:
+		return v, true, true
+	case json.Number:
+		return v.String(), true, true
+	case bool:
+		return strconv.FormatBool(v), true, true
+	case nil:
+		return "", true, true
+	default:
+		return "", true, false
+	}
+}
+
+// selectLeaf resolves PATH against an already loaded document.
+func selectLeaf(scheme, path string, load func(source string) (any, error)) (string, error) {
+	source, selector, ok := splitDocumentPath(path)
+	if !ok || selector == "" {
+		return "", NewExitError("EVE-104-603", scheme, path)
+	}
+	doc, err := load(source)
+	if err != nil {
+		return "", err
+	}
+	value, found, scalar := selectDocumentValue(doc, selector)
+	if !found {
+		return "", NewExitError("EVE-104-204", selector, scheme, source)
+	}
+	if !scalar {
+		return "", NewExitError("EVE-104-602", selector, scheme, source)
+	}
+	return value, nil
+}
+
+// showDocumentValue implements Show for a DocumentClient without caching.
+func showDocumentValue(ctx context.Context, client DocumentClient, scheme, path string) (string, error) {
+	return selectLeaf(scheme, path, func(source string) (any, error) {
+		return client.LoadDocument(ctx, source)
+	})
+}
@@ -43,12 +43,21 @@ type ErrorDetailEntry struct {
 
 var errorRegistry = map[string]ErrorDetail{
 	// 101 CLI / Input & Path Resolution (sorted by subcode)
-	"EVE-101-1":   {Exit: ExitInvalidInput, Message: "no command specified", Detail: "No subcommand was provided. Specify a valid subcommand: `sync`, `diff`, `validate`, or `version`. See `envseed --help` for an overview and `envseed <command> --help` for usage.", DocSlug: "docs/errors.md#eve-101-1"},
-	"EVE-101-2":   {Exit: ExitInvalidInput, Message: "unknown command %q", Detail: "An unsupported subcommand was provided. Use a valid subcommand: `sync`, `diff`, `validate`, or `version`. See `envseed --help` and `envseed <command> --help` for usage.", DocSlug: "docs/errors.md#eve-101-2"},
+	"EVE-101-1":   {Exit: ExitInvalidInput, Message: "no command specified", Detail: "No subcommand was provided. Specify a valid subcommand: `sync`, `diff`, `validate`, `import`, `push`, `agent`, or `version`. See `envseed --help` for an overview and `envseed <command> --help` for usage.", DocSlug: "docs/errors.md#eve-101-1"},
+	"EVE-101-2":   {Exit: ExitInvalidInput, Message: "unknown command %q", Detail: "An unsupported subcommand was provided. Use a valid subcommand: `sync`, `diff`, `validate`, `import`, `push`, `agent`, or `version`. See `envseed --help` and `envseed <command> --help` for usage.", DocSlug: "docs/errors.md#eve-101-2"},
 	"EVE-101-3":   {Exit: ExitInvalidInput, Message: "unsupported flag combination", Detail: "The provided flags conflict or are not supported together. Remove the conflicting flags. See `envseed <command> --help` for supported combinations.", DocSlug: "docs/errors.md#eve-101-3"},
 	"EVE-101-4":   {Exit: ExitInvalidInput, Message: "version command does not accept flags or arguments", Detail: "Flags or arguments were provided to `version`. Run `envseed version` with no flags or arguments. See `envseed version --help` for details.", DocSlug: "docs/errors.md#eve-101-4"},
 	"EVE-101-5":   {Exit: ExitInvalidInput, Message: "unknown or invalid flag %q", Detail: "An unknown or invalid flag was provided. Remove or correct the flag. See `envseed <command> --help` for supported options.", DocSlug: "docs/errors.md#eve-101-5"},
 	"EVE-101-6":   {Exit: ExitInvalidInput, Message: "unexpected positional arguments", Detail: "Too many positional arguments were provided. Provide at most one optional INPUT_FILE.", DocSlug: "docs/errors.md#eve-101-6"},
+	"EVE-101-7":   {Exit: ExitInvalidInput, Message: "unsupported pass backend %q", Detail: "The pass backend selected with `--pass-backend` or `ENVSEED_PASS_BACKEND` is not supported. Use `pass`, `gopass`, or `native`.", DocSlug: "docs/errors.md#eve-101-7"},
+	"EVE-101-8":   {Exit: ExitInvalidInput, Message: "invalid job count %d", Detail: "The value of `--jobs` must be at least 1. Use `--jobs 1` to resolve placeholders one at a time.", DocSlug: "docs/errors.md#eve-101-8"},
+	"EVE-101-9":   {Exit: ExitInvalidInput, Message: "missing required flag %q", Detail: "The command needs this flag. For example, `envseed import` needs `--prefix` to know the pass folder the values are stored under: `envseed import --prefix myapp/dev .env`.", DocSlug: "docs/errors.md#eve-101-9"},
+	"EVE-101-10":  {Exit: ExitInvalidInput, Message: "invalid pass prefix %q", Detail: "The `--prefix` of `envseed import` must be a pass folder such as `myapp/dev`: non-empty `/`-separated segments other than `.` and `..`, without whitespace, control characters, `|`, `<`, or `>`.", DocSlug: "docs/errors.md#eve-101-10"},
+	"EVE-101-11":  {Exit: ExitInvalidInput, Message: "invalid keep pattern %q", Detail: "A `--keep` pattern of `envseed import` is malformed. Patterns match variable names with `*`, `?`, and `[...]` classes, e.g. `--keep 'NODE_ENV' --keep '*_URL'`; close every `[`.", DocSlug: "docs/errors.md#eve-101-11"},
+	"EVE-101-12":  {Exit: ExitInvalidInput, Message: "confirmation required", Detail: "`envseed push` asks before updating entries, but stdin is not a terminal. Review the changes with `envseed push --dry-run`, then run `envseed push --yes` to update the entries without asking.", DocSlug: "docs/errors.md#eve-101-12"},
+	"EVE-101-13":  {Exit: ExitInvalidInput, Message: "invalid value %q for %s", Detail: "`envseed agent --ttl` must be a positive duration such as `15m` or `1h`, and `--max-uses` must be 0 (unlimited) or more.", DocSlug: "docs/errors.md#eve-101-13"},
+	"EVE-101-14":  {Exit: ExitInvalidInput, Message: "invalid profile name %q", Detail: "The value of `--profile` must be a name such as `dev` or `prod-eu`: ASCII letters, digits, `_`, `-`, and `.`, not starting with `.`.", DocSlug: "docs/errors.md#eve-101-14"},
+	"EVE-101-15":  {Exit: ExitInvalidInput, Message: "unknown profile %q", Detail: "The template declares its profiles with `#@vars` directives, and the profile selected with `--profile` is not one of them. Select a declared profile or add `#@vars PROFILE` to the template.", DocSlug: "docs/errors.md#eve-101-15"},
 	"EVE-101-101": {Exit: ExitInvalidInput, Message: "stdin is not supported", Detail: "This command intentionally does not accept stdin for templates for safety and reproducibility. Provide a readable file path instead of stdin. See `envseed <command> --help` for argument usage.", DocSlug: "docs/errors.md#eve-101-101"},
 	"EVE-101-201": {Exit: ExitInvalidInput, Message: "input file %q must contain `envseed` when `--output` is omitted", Detail: "Omitting `--output` requires the template filename to contain `envseed`. Include `envseed` in the template filename or supply `--output`. See `envseed <command> --help` for usage.", DocSlug: "docs/errors.md#eve-101-201"},
 	"EVE-101-301": {Exit: ExitInvalidInput, Message: "output path %q is a directory", Detail: "The output path resolves to a directory. Choose a path that resolves to a regular file. Specify the output file explicitly with `--output` when needed.", DocSlug: "docs/errors.md#eve-101-301"},
@@ -68,32 +77,85 @@ var errorRegistry = map[string]ErrorDetail{
 	"EVE-103-1":   {Exit: ExitTemplateParse, Message: "non-ASCII whitespace around placeholder separators or before `>`", Detail: "Non‑ASCII whitespace was detected around `|`, `,`, or before `>`. Use ASCII SPACE or TAB only. For example: NG: `<pass:api_key | base64>`. OK: `<pass:api_key|base64>`.", DocSlug: "docs/errors.md#eve-103-1"},
 	"EVE-103-2":   {Exit: ExitTemplateParse, Message: "non-ASCII whitespace adjacent to placeholder PATH", Detail: "Non‑ASCII whitespace was detected adjacent to the placeholder path. Use ASCII SPACE or TAB only when trimming around the placeholder path. For example: NG uses U+00A0 between `:` and `api_key`: `<pass:api_key|...>`. OK: `<pass:api_key|...>`.", DocSlug: "docs/errors.md#eve-103-2"},
 	"EVE-103-3":   {Exit: ExitTemplateParse, Message: "non-ASCII whitespace at start of line", Detail: "Leading whitespace contains non‑ASCII characters. Use ASCII SPACE or TAB only and avoid Unicode spaces such as U+00A0.", DocSlug: "docs/errors.md#eve-103-3"},
-	"EVE-103-4":   {Exit: ExitTemplateParse, Message: "whitespace between `pass` and `:`", Detail: "Whitespace was inserted between `pass` and `:`. Do not add whitespace there. For example: NG: `<pass :path|...>`. OK: `<pass:path|...>`.", DocSlug: "docs/errors.md#eve-103-4"},
+	"EVE-103-4":   {Exit: ExitTemplateParse, Message: "whitespace between placeholder scheme and `:`", Detail: "Whitespace was inserted between the placeholder scheme (such as `pass` or `env`) and `:`. Do not add whitespace there. For example: NG: `<pass :path|...>`. OK: `<pass:path|...>`.", DocSlug: "docs/errors.md#eve-103-4"},
+	"EVE-103-5":   {Exit: ExitTemplateParse, Message: "cannot read included template %q", Detail: "An `#@include` directive names a template that cannot be read. The path is resolved relative to the directory of the including file; check that it exists and is readable.", DocSlug: "docs/errors.md#eve-103-5"},
+	"EVE-103-6":   {Exit: ExitTemplateParse, Message: "include cycle through %q", Detail: "An `#@include` directive includes a template that is already being included, directly or through other files. Remove the directive that closes the cycle.", DocSlug: "docs/errors.md#eve-103-6"},
+	"EVE-103-7":   {Exit: ExitTemplateParse, Message: "includes nested deeper than %d levels", Detail: "`#@include` directives are nested too deeply. Flatten the includes so that no chain of included templates exceeds the limit.", DocSlug: "docs/errors.md#eve-103-7"},
+	"EVE-103-8":   {Exit: ExitTemplateParse, Message: "missing path in `#@include` directive", Detail: "An `#@include` directive has no path. Write the template to include after the directive, e.g., `#@include ../shared/.envseed.common`.", DocSlug: "docs/errors.md#eve-103-8"},
+	"EVE-103-9":   {Exit: ExitTemplateParse, Message: "malformed `#@vars` directive", Detail: "A `#@vars` directive must name a profile followed by `NAME=VALUE` variables separated by spaces, e.g., `#@vars prod prefix=myapp/prod`. Profile names use ASCII letters, digits, `_`, `-`, and `.`; `profile` is reserved; values cannot contain whitespace, control characters, `<`, `>`, `|`, `{`, or `}`.", DocSlug: "docs/errors.md#eve-103-9"},
+	"EVE-103-10":  {Exit: ExitTemplateParse, Message: "undefined template variable %q in profile %q", Detail: "A placeholder path references `{{NAME}}`, but no `#@vars` directive defines NAME for the selected profile. Define it, e.g., `#@vars prod NAME=value`, for every profile, or use `{{profile}}`.", DocSlug: "docs/errors.md#eve-103-10"},
+	"EVE-103-11":  {Exit: ExitTemplateParse, Message: "template variables require a profile", Detail: "A placeholder path references a `{{NAME}}` template variable, but no profile is selected. Select one with `--profile`, e.g., `envseed sync --profile dev`. `envseed validate` checks every profile declared with `#@vars`.", DocSlug: "docs/errors.md#eve-103-11"},
+	"EVE-103-12":  {Exit: ExitTemplateParse, Message: "malformed template variable reference %q", Detail: "`{{` in a placeholder path starts a template variable reference, which must be a name closed by `}}`, e.g., `<pass:{{profile}}/db/password>`.", DocSlug: "docs/errors.md#eve-103-12"},
 	"EVE-103-101": {Exit: ExitTemplateParse, Message: "invalid assignment name", Detail: "The assignment name is invalid. Use ASCII letters, digits, or underscore, and do not leave the name empty. For example: valid `FOO_1`. Invalid `1FOO`.", DocSlug: "docs/errors.md#eve-103-101"},
 	"EVE-103-102": {Exit: ExitTemplateParse, Message: "missing '=' in assignment", Detail: "The assignment is missing `=` or `+=` between name and value. Ensure the operator is present. For example: NG: `NAME value`. OK: `NAME=value`.", DocSlug: "docs/errors.md#eve-103-102"},
 	"EVE-103-103": {Exit: ExitTemplateParse, Message: "unexpected line; expected an assignment", Detail: "A non‑blank line is neither an assignment nor a comment. Each non‑blank line must be an assignment or a comment; blank lines are allowed.", DocSlug: "docs/errors.md#eve-103-103"},
-	"EVE-103-201": {Exit: ExitTemplateParse, Message: "empty placeholder path", Detail: "The placeholder path is empty. Provide a non‑empty path inside `<pass:...>`. For example: NG: `<pass:|...>`. OK: `<pass:secret/path|...>`.", DocSlug: "docs/errors.md#eve-103-201"},
+	"EVE-103-104": {Exit: ExitTemplateParse, Message: "unsupported declaration %q", Detail: "Only `export`, `readonly`, and `declare -x` may precede an assignment name, without other options. For example: NG: `declare -a NAME=value` or `export -n NAME=value`. OK: `export NAME=value`.", DocSlug: "docs/errors.md#eve-103-104"},
+	"EVE-103-201": {Exit: ExitTemplateParse, Message: "empty placeholder path", Detail: "The placeholder path is empty. Provide a non‑empty path inside the placeholder such as `<pass:...>`. For example: NG: `<pass:|...>`. OK: `<pass:secret/path|...>`.", DocSlug: "docs/errors.md#eve-103-201"},
 	"EVE-103-202": {Exit: ExitTemplateParse, Message: "unterminated placeholder", Detail: "The placeholder is unterminated. Close placeholders with `>` and ensure all modifiers are complete. For example: NG: `<pass:api_key|allow_newline`. OK: `<pass:api_key|allow_newline>`.", DocSlug: "docs/errors.md#eve-103-202"},
 	"EVE-103-203": {Exit: ExitTemplateParse, Message: "placeholder path contains NUL byte", Detail: "The placeholder path contains a NUL byte. Remove NUL bytes U+0000 from the path.", DocSlug: "docs/errors.md#eve-103-203"},
 	"EVE-103-205": {Exit: ExitTemplateParse, Message: "template contains NUL byte", Detail: "The template contains a NUL byte (U+0000). Remove NUL bytes from the input.", DocSlug: "docs/errors.md#eve-103-205"},
 	"EVE-103-204": {Exit: ExitTemplateParse, Message: "placeholder path contains non-ASCII whitespace", Detail: "Non‑ASCII whitespace was found around the placeholder path. Use ASCII SPACE or TAB only.", DocSlug: "docs/errors.md#eve-103-204"},
 	"EVE-103-301": {Exit: ExitTemplateParse, Message: "missing placeholder modifiers after '|'", Detail: "The `|` separator was present but no modifiers were provided after it. List at least one modifier after `|`. For example: `<pass:api_key|allow_newline>`.", DocSlug: "docs/errors.md#eve-103-301"},
-	"EVE-103-302": {Exit: ExitTemplateParse, Message: "unknown placeholder modifier %q", Detail: "An unknown placeholder modifier was provided. Use only supported modifiers: `allow_newline`, `allow_tab`, `base64`, `strip`, `strip_left`, `strip_right`, `dangerously_bypass_escape`.", DocSlug: "docs/errors.md#eve-103-302"},
+	"EVE-103-302": {Exit: ExitTemplateParse, Message: "unknown placeholder modifier %q", Detail: "An unknown placeholder modifier was provided. Use only supported modifiers: `allow_newline`, `allow_tab`, `base64`, `base64_decode`, `base64url`, `base64_nopad`, `hex`, `urlencode`, `strip`, `strip_left`, `strip_right`, `first_line`, `line=N`, `field=NAME`, `optional`, `default=VALUE`, `gen_len=N`, `gen_charset=NAME`, `dangerously_bypass_escape`.", DocSlug: "docs/errors.md#eve-103-302"},
 	"EVE-103-303": {Exit: ExitTemplateParse, Message: "duplicate placeholder modifier %q", Detail: "A placeholder modifier was repeated. Specify each modifier at most once. For example: NG: `<pass:api_key|strip,strip>`. OK: `<pass:api_key|strip>`.", DocSlug: "docs/errors.md#eve-103-303"},
 	"EVE-103-304": {Exit: ExitTemplateParse, Message: "empty placeholder modifier", Detail: "An empty placeholder modifier was found. Remove empty entries between commas. For example: NG: `<pass:api_key|strip,,base64>`.", DocSlug: "docs/errors.md#eve-103-304"},
 	"EVE-103-305": {Exit: ExitTemplateParse, Message: "invalid whitespace or NUL byte in placeholder modifiers", Detail: "Invalid whitespace or NUL bytes were found in placeholder modifiers. Use ASCII SPACE or TAB only and remove NUL bytes.", DocSlug: "docs/errors.md#eve-103-305"},
+	"EVE-103-306": {Exit: ExitTemplateParse, Message: "invalid argument for placeholder modifier %q", Detail: "A modifier argument is missing or malformed (including whitespace around `=`, characters not allowed in a bare argument, or text after a closing quote), or a value was given to a modifier that takes none. `line=N` requires a decimal N >= 1 and `field=NAME` a name of ASCII letters, digits, `_`, `-`, or `.`. For example: OK: `<pass:site|field=user>`; NG: `<pass:site|line=0>`, `<pass:site|strip=1>`.", DocSlug: "docs/errors.md#eve-103-306"},
+	"EVE-103-307": {Exit: ExitTemplateParse, Message: "unterminated quoted argument for placeholder modifier %q", Detail: "A double-quoted modifier argument is not closed before the end of the line. Close the argument with `\"`; write a literal quote inside it as `\\\"`. For example: NG: `<pass:site|field=\"user>`; OK: `<pass:site|field=\"user\">`.", DocSlug: "docs/errors.md#eve-103-307"},
+	"EVE-103-308": {Exit: ExitTemplateParse, Message: "invalid escape sequence in argument of placeholder modifier %q", Detail: "A double-quoted modifier argument contains a backslash that is not part of `\\\"` or `\\\\`, the only supported escape sequences. Double the backslash to write it literally, or remove it. For example: NG: `<pass:site|field=\"us\\er\">`; OK: `<pass:site|field=\"user\">`.", DocSlug: "docs/errors.md#eve-103-308"},
+	"EVE-103-309": {Exit: ExitTemplateParse, Message: "placeholder modifier %q cannot follow %q", Detail: "Modifiers that transform the value apply left to right, and an encoded value has no lines or whitespace left to select or strip. Place selectors, the strip family and `base64_decode` before the first encoding; only further encodings may follow it. `allow_*`, `optional` and `default=VALUE` may appear anywhere. For example: NG: `<pass:site|base64,strip>`; OK: `<pass:site|strip,base64>`.", DocSlug: "docs/errors.md#eve-103-309"},
+	"EVE-103-310": {Exit: ExitTemplateParse, Message: "placeholder modifier %q requires the pass scheme", Detail: "`gen_len=N` and `gen_charset=NAME` configure the entries `envseed sync --generate-missing` creates, which are always `pass` entries. Remove them from placeholders of other schemes. For example: NG: `<env:TOKEN|gen_len=48>`; OK: `<pass:app/token|gen_len=48>`.", DocSlug: "docs/errors.md#eve-103-310"},
 	"EVE-103-401": {Exit: ExitTemplateParse, Message: "unterminated double quote", Detail: "A double‑quoted string is unterminated. Close the string before the line ends. For example: NG: `NAME=\"value`.", DocSlug: "docs/errors.md#eve-103-401"},
 	"EVE-103-402": {Exit: ExitTemplateParse, Message: "unterminated single quote", Detail: "A single‑quoted string is unterminated. Close the string before the line ends. For example: NG: `NAME='value`.", DocSlug: "docs/errors.md#eve-103-402"},
 	"EVE-103-403": {Exit: ExitTemplateParse, Message: "unterminated backtick substitution", Detail: "A backtick command substitution is unterminated. Close the substitution. For example: NG: `` NAME=`cmd `.", DocSlug: "docs/errors.md#eve-103-403"},
 	"EVE-103-404": {Exit: ExitTemplateParse, Message: "unterminated command substitution", Detail: "A `$()` command substitution is unterminated. Ensure the opening and closing parentheses match. For example: NG: `NAME=$(cmd`.", DocSlug: "docs/errors.md#eve-103-404"},
+	"EVE-103-405": {Exit: ExitTemplateParse, Message: "unterminated $'...' quote", Detail: "An ANSI-C `$'...'` string is unterminated. Close the string; inside it, write a single quote as `\\'`. For example: NG: `NAME=$'value`.", DocSlug: "docs/errors.md#eve-103-405"},
+	"EVE-103-406": {Exit: ExitTemplateParse, Message: "unterminated parameter expansion", Detail: "A `${...}` parameter expansion is unterminated. Close it with `}`; a `}` that belongs to the word must be escaped as `\\}`. For example: NG: `NAME=${HOST:-localhost`.", DocSlug: "docs/errors.md#eve-103-406"},
 	"EVE-103-501": {Exit: ExitTemplateParse, Message: "mismatched brackets in assignment name", Detail: "Brackets in the assignment name are mismatched. Balance `[` and `]`. For example: NG: `ARR[0=value`.", DocSlug: "docs/errors.md#eve-103-501"},
 	"EVE-103-502": {Exit: ExitTemplateParse, Message: "unexpected `]` in assignment", Detail: "An unexpected `]` was found in the assignment name. Check bracket usage. For example: NG: `ARR]0=value`.", DocSlug: "docs/errors.md#eve-103-502"},
 
-	// 104 Resolver (pass)
-	"EVE-104-1":   {Exit: ExitResolverFailure, Message: "pass command not found", Detail: "The `pass` CLI is not available. Install `pass` and ensure it is available in `PATH`.", DocSlug: "docs/errors.md#eve-104-1"},
-	"EVE-104-101": {Exit: ExitResolverFailure, Message: "pass show %q failed", Detail: "The `pass` command returned an error for the requested entry. Run `pass show <PATH>` to see the underlying cause and resolve the issue such as a missing entry or a permission error.", DocSlug: "docs/errors.md#eve-104-101"},
-	"EVE-104-201": {Exit: ExitResolverFailure, Message: "pass entry %q not found", Detail: "The requested `pass` entry was not found. Create the entry or correct the placeholder path. For example: `pass insert <PATH>`.", DocSlug: "docs/errors.md#eve-104-201"},
+	// 104 Resolver (pass and other placeholder schemes)
+	"EVE-104-1":   {Exit: ExitResolverFailure, Message: "%s command not found", Detail: "A CLI required by a resolver is not available: the selected pass backend (`pass`, `gopass`, or `gpg` for `native`), a scheme backend such as `sops`, `op`, or `bw`, or the `envseed-resolver-NAME` executable of a plugin scheme `x-NAME`. Install it and ensure it is available in `PATH`, or select another pass backend with `--pass-backend`.", DocSlug: "docs/errors.md#eve-104-1"},
+	"EVE-104-101": {Exit: ExitResolverFailure, Message: "%s show %q failed", Detail: "The pass backend returned an error for the requested entry. Run `pass show <PATH>` (or `gopass show <PATH>`) to see the underlying cause and resolve the issue such as a missing entry or a permission error.", DocSlug: "docs/errors.md#eve-104-101"},
+	"EVE-104-102": {Exit: ExitResolverFailure, Message: "failed to read file %q", Detail: "The file referenced by a `<file:...>`, `<age:...>`, or `<sops:...>` placeholder could not be read. Check read permissions and that the path names a regular file. Relative paths are resolved against the template's directory.", DocSlug: "docs/errors.md#eve-104-102"},
+	"EVE-104-103": {Exit: ExitResolverFailure, Message: "failed to decrypt age file %q", Detail: "The file referenced by an `<age:...>` placeholder could not be decrypted with the configured identity. Check that the file was encrypted to a recipient of the identity and is not corrupted.", DocSlug: "docs/errors.md#eve-104-103"},
+	"EVE-104-104": {Exit: ExitResolverFailure, Message: "sops failed to decrypt %q", Detail: "`sops --decrypt` returned an error for the file referenced by a `<sops:...>` placeholder. Run `sops --decrypt <FILE>` to see the underlying cause, such as missing key access.", DocSlug: "docs/errors.md#eve-104-104"},
+	"EVE-104-105": {Exit: ExitResolverFailure, Message: "vault request for %q failed", Detail: "The Vault server could not be reached or answered a `<vault:...>` read with an unexpected status. Check `VAULT_ADDR`, network access, and the server status; the wrapped error carries Vault's own message.", DocSlug: "docs/errors.md#eve-104-105"},
+	"EVE-104-106": {Exit: ExitResolverFailure, Message: "%s failed to read %q", Detail: "The password manager CLI behind an `<op:...>` or `<bw:...>` placeholder returned an error that is not a missing entry, a locked vault, or an expired session. Run `op read op://<PATH>` or `bw get password <ITEM>` to see the underlying cause.", DocSlug: "docs/errors.md#eve-104-106"},
+	"EVE-104-107": {Exit: ExitResolverFailure, Message: "%s plugin failed to resolve %q", Detail: "The resolver plugin of an `<x-NAME:...>` placeholder reported an error, or exited with a failure status without a valid response. The plugin's message is attached; run `envseed-resolver-NAME` by hand to investigate.", DocSlug: "docs/errors.md#eve-104-107"},
+	"EVE-104-108": {Exit: ExitResolverFailure, Message: "%s plugin returned an invalid response for %q", Detail: "The resolver plugin of an `<x-NAME:...>` placeholder did not answer with a single protocol version 1 JSON object carrying either `value` or `error`. Update the plugin to the protocol described in the specification (Section 6.2).", DocSlug: "docs/errors.md#eve-104-108"},
+	"EVE-104-201": {Exit: ExitResolverFailure, Message: "%s entry %q not found", Detail: "The requested entry was not found by the selected `pass` backend, 1Password (`op`), Bitwarden (`bw`), or a resolver plugin. Create the entry or correct the placeholder path, including any gopass mount prefix or the vault/item/field of a 1Password reference. For example: `pass insert <PATH>`.", DocSlug: "docs/errors.md#eve-104-201"},
+	"EVE-104-202": {Exit: ExitResolverFailure, Message: "environment variable %q is not set", Detail: "The variable referenced by an `<env:...>` placeholder is not set in the environment of `envseed`. Export the variable or correct the placeholder name.", DocSlug: "docs/errors.md#eve-104-202"},
+	"EVE-104-203": {Exit: ExitResolverFailure, Message: "file %q not found", Detail: "The file referenced by a `<file:...>`, `<age:...>`, or `<sops:...>` placeholder does not exist. Relative paths are resolved against the template's directory. Create the file or correct the placeholder path.", DocSlug: "docs/errors.md#eve-104-203"},
+	"EVE-104-204": {Exit: ExitResolverFailure, Message: "key %q not found in %s document %q", Detail: "The key path after `#` does not exist in the decrypted document. Keys are separated by `.` and list elements are selected by their index (e.g., `db.hosts.0`). Correct the key path or add the key to the document.", DocSlug: "docs/errors.md#eve-104-204"},
+	"EVE-104-205": {Exit: ExitResolverFailure, Message: "vault secret %q not found", Detail: "Vault answered 404 for the path of a `<vault:...>` placeholder. The path is the API path below `/v1/`, so KV v2 mounts need the `data/` segment (e.g., `secret/data/app`). Create the secret or correct the path.", DocSlug: "docs/errors.md#eve-104-205"},
+	"EVE-104-206": {Exit: ExitResolverFailure, Message: "entry %q has no otpauth URI", Detail: "The entry read for an `<otp:...>` placeholder has no line starting with `otpauth://`. Store the key URI in the entry, e.g. with `pass otp append <PATH>`, or correct the placeholder path.", DocSlug: "docs/errors.md#eve-104-206"},
 	"EVE-104-301": {Exit: ExitResolverFailure, Message: "pass entry %q contains NUL byte", Detail: "The `pass` entry value contains a NUL byte. Remove NUL characters U+0000 from the value.", DocSlug: "docs/errors.md#eve-104-301"},
+	"EVE-104-302": {Exit: ExitResolverFailure, Message: "%s value %q contains NUL byte", Detail: "The value resolved for a non-`pass` placeholder contains a NUL byte. Remove NUL characters U+0000 from the source value.", DocSlug: "docs/errors.md#eve-104-302"},
+	"EVE-104-401": {Exit: ExitResolverFailure, Message: "no resolver registered for placeholder scheme %q", Detail: "The template uses a placeholder scheme for which no resolver is registered in this run. Use a supported scheme (`pass`, `env`, `file`, `age`, `sops`, `vault`, `op`, `bw`, `otp`), a plugin scheme `x-NAME`, or register a resolver for the scheme.", DocSlug: "docs/errors.md#eve-104-401"},
+	"EVE-104-402": {Exit: ExitResolverFailure, Message: "age identity is not configured", Detail: "The template uses `<age:...>` placeholders but no identity file is configured. Pass `--age-identity <FILE>` or set `ENVSEED_AGE_IDENTITY`.", DocSlug: "docs/errors.md#eve-104-402"},
+	"EVE-104-403": {Exit: ExitResolverFailure, Message: "failed to load age identity %q", Detail: "The configured age identity file could not be read or does not contain valid identities. Check the path and that the file was produced by `age-keygen`.", DocSlug: "docs/errors.md#eve-104-403"},
+	"EVE-104-404": {Exit: ExitResolverFailure, Message: "vault address is not configured", Detail: "The template uses `<vault:...>` placeholders but `VAULT_ADDR` is not set. Export the server address (e.g., `https://vault.example.com:8200`).", DocSlug: "docs/errors.md#eve-104-404"},
+	"EVE-104-405": {Exit: ExitResolverFailure, Message: "vault token is not configured", Detail: "The template uses `<vault:...>` placeholders but neither `VAULT_TOKEN` nor `~/.vault-token` provides a token. Run `vault login` or export `VAULT_TOKEN`.", DocSlug: "docs/errors.md#eve-104-405"},
+	"EVE-104-501": {Exit: ExitResolverFailure, Message: "%s denied access to %q", Detail: "The backend refused to read the path: Vault answered 403 for a `<vault:...>` placeholder, or a resolver plugin reported `permission_denied`. For Vault, the token has expired or its policies do not grant `read` on the path; renew the token with `vault login` or ask for a policy that covers the path.", DocSlug: "docs/errors.md#eve-104-501"},
+	"EVE-104-502": {Exit: ExitResolverFailure, Message: "%s vault is locked", Detail: "The password manager vault is locked, so the CLI cannot read entries without prompting. Unlock it first: unlock the 1Password app, or run `bw unlock` and export the printed `BW_SESSION`. Resolver plugins report this state as `locked`.", DocSlug: "docs/errors.md#eve-104-502"},
+	"EVE-104-503": {Exit: ExitResolverFailure, Message: "%s session has expired", Detail: "The password manager CLI is not signed in or its session has expired. Sign in again with `op signin` (1Password) or `bw login` / `bw unlock` and export a fresh `BW_SESSION` (Bitwarden). Resolver plugins report this state as `unauthenticated`.", DocSlug: "docs/errors.md#eve-104-503"},
+	"EVE-104-601": {Exit: ExitResolverFailure, Message: "failed to parse decrypted %s document %q", Detail: "The backend returned a document that could not be parsed. Check that the file is a valid encrypted document for the backend, or that the Vault path names a KV secret.", DocSlug: "docs/errors.md#eve-104-601"},
+	"EVE-104-602": {Exit: ExitResolverFailure, Message: "key %q in %s document %q is not a scalar value", Detail: "The key path selects a map or list. Select a string, number, boolean, or null leaf instead.", DocSlug: "docs/errors.md#eve-104-602"},
+	"EVE-104-603": {Exit: ExitResolverFailure, Message: "%s placeholder %q has no `#KEY` selector", Detail: "Placeholders of document schemes must select a leaf with `SOURCE#key.path` (e.g., `<sops:secrets/prod.yaml#db.password>`). Add the key path after `#`.", DocSlug: "docs/errors.md#eve-104-603"},
+	"EVE-104-604": {Exit: ExitResolverFailure, Message: "invalid otpauth URI in entry %q", Detail: "The `otpauth://` URI of an `<otp:...>` entry is malformed: it is not a `totp` URI, its `secret` is missing or not base32, `digits` is not 6, 7, or 8, or `period` is not a positive number of seconds. Re-create the key URI from the issuer's enrollment QR code.", DocSlug: "docs/errors.md#eve-104-604"},
+	"EVE-104-605": {Exit: ExitResolverFailure, Message: "unsupported OTP algorithm %q in entry %q", Detail: "The `algorithm` parameter of the `otpauth://` URI is not one of `SHA1`, `SHA256`, or `SHA512`. Check the key URI provided by the issuer.", DocSlug: "docs/errors.md#eve-104-605"},
+	"EVE-104-701": {Exit: ExitResolverFailure, Message: "%s backend cannot create entries", Detail: "`sync --generate-missing` and `import` create entries through the pass backend, and the configured backend does not support it. Use the `pass`, `gopass`, or `native` backend, or create the entry yourself with `pass generate <PATH>`.", DocSlug: "docs/errors.md#eve-104-701"},
+	"EVE-104-702": {Exit: ExitResolverFailure, Message: "%s insert %q failed", Detail: "The pass backend could not create a generated or imported entry (an existing entry is never replaced). Check that the store is writable and that `.gpg-id` names keys available to gpg; the wrapped error carries the backend's message. Entries created before the failure are listed on stderr and kept.", DocSlug: "docs/errors.md#eve-104-702"},
+	"EVE-104-703": {Exit: ExitResolverFailure, Message: "gen_charset %q for %q cannot be rendered at line %d", Detail: "A generated value must render in every placeholder of its entry. The character set contains characters that the placeholder's context rejects (e.g., `'` in single quotes, or `symbols` in a bare value). Choose a narrower `gen_charset`, such as `alnum`, or move the placeholder to a context that accepts the characters. Nothing is created.", DocSlug: "docs/errors.md#eve-104-703"},
+	"EVE-104-704": {Exit: ExitResolverFailure, Message: "conflicting gen_len or gen_charset for %q at line %d", Detail: "Placeholders of the same `pass` entry set different `gen_len=N` or `gen_charset=NAME` values, so it is unclear which entry to generate. Give every placeholder of the entry the same values, or set them on one placeholder only.", DocSlug: "docs/errors.md#eve-104-704"},
+	"EVE-104-705": {Exit: ExitResolverFailure, Message: "%s entry %q already exists", Detail: "`envseed import` creates a new entry for every imported value and never replaces an existing one. Choose another `--prefix`, keep the variable literal with `--keep NAME`, or remove the entry with `pass rm <PATH>` if it is stale. Nothing was stored.", DocSlug: "docs/errors.md#eve-104-705"},
+	"EVE-104-706": {Exit: ExitResolverFailure, Message: "%s backend cannot update entries", Detail: "`envseed push` replaces entries through the pass backend, and the configured backend does not support it. Use the `pass`, `gopass`, or `native` backend, or update the entry yourself with `pass edit <PATH>`.", DocSlug: "docs/errors.md#eve-104-706"},
+	"EVE-104-707": {Exit: ExitResolverFailure, Message: "%s update %q failed", Detail: "The pass backend could not replace an entry. Check that the store is writable and that `.gpg-id` names keys available to gpg; the wrapped error carries the backend's message. Entries updated before the failure are listed on stderr and keep their new values.", DocSlug: "docs/errors.md#eve-104-707"},
+	"EVE-104-801": {Exit: ExitResolverFailure, Message: "agent not running at %q", Detail: "No agent answers on the socket, or its folder is not a private directory of the current user. Start one with `envseed agent`, and use the same `ENVSEED_AGENT_SOCK` (or `--socket`) for the agent and the command.", DocSlug: "docs/errors.md#eve-104-801"},
+	"EVE-104-802": {Exit: ExitResolverFailure, Message: "agent already running at %q", Detail: "Another agent listens on the socket. Use it, stop it first with `envseed agent --stop`, or start the new agent on another socket with `--socket PATH`.", DocSlug: "docs/errors.md#eve-104-802"},
+	"EVE-104-803": {Exit: ExitResolverFailure, Message: "agent socket %q cannot be used", Detail: "The agent could not listen on the socket. Its folder must be a directory owned by you with mode 0700 (e.g. `$XDG_RUNTIME_DIR/envseed`), so that no other user can reach the cached secrets; the wrapped error carries the reason.", DocSlug: "docs/errors.md#eve-104-803"},
+	"EVE-104-804": {Exit: ExitResolverFailure, Message: "agent at %q failed", Detail: "The agent did not answer the request as expected; it may be a different envseed version. Restart it with `envseed agent --stop` followed by `envseed agent`.", DocSlug: "docs/errors.md#eve-104-804"},
 
 	// 105 Rendering + Re-parse Validation
 	"EVE-105-1": {Exit: ExitRenderError, Message: "rendering failed due to placeholder constraints", Detail: "The secret cannot be represented in the chosen placeholder context without violating constraints. Adjust quoting or add the required modifiers such as `allow_newline` or `allow_tab`, or choose a different quoting context.", DocSlug: "docs/errors.md#eve-105-1"},
@@ -122,9 +184,22 @@ var errorRegistry = map[string]ErrorDetail{
 	"EVE-105-503": {Exit: ExitRenderError, Message: "control character U+%04X not permitted in bare placeholder", Detail: "Control characters are not supported in bare placeholders. Quote or encode the value to avoid emitting unsupported control characters.", DocSlug: "docs/errors.md#eve-105-503"},
 	"EVE-105-504": {Exit: ExitRenderError, Message: "allow_newline modifier is not supported in bare context", Detail: "The `allow_newline` modifier is not supported in bare context. Switch to double quotes and add the `allow_newline` modifier.", DocSlug: "docs/errors.md#eve-105-504"},
 	// B6: Invalid modifier combination
-	"EVE-105-601": {Exit: ExitRenderError, Message: "invalid placeholder modifier combination", Detail: "The `base64` modifier cannot be combined with any other modifier. Remove the other modifiers, including the strip family and `dangerously_bypass_escape`.", DocSlug: "docs/errors.md#eve-105-601"},
+	"EVE-105-601": {Exit: ExitRenderError, Message: "invalid placeholder modifier combination", Detail: "`dangerously_bypass_escape` cannot be combined with any other modifier, and `allow_tab` and `allow_newline` cannot be combined with a modifier pipeline that ends in an encoding (`base64`, `base64url`, `base64_nopad`, `hex`, `urlencode`), whose output never contains TAB or newlines. Remove the conflicting modifiers.", DocSlug: "docs/errors.md#eve-105-601"},
+	"EVE-105-602": {Exit: ExitRenderError, Message: "conflicting selector modifiers", Detail: "At most one of `first_line`, `line=N`, and `field=NAME` may be used per placeholder. Keep a single selector, or use separate placeholders for separate parts of the entry.", DocSlug: "docs/errors.md#eve-105-602"},
 	// B7: Post-render re-parse validation failure
 	"EVE-105-701": {Exit: ExitRenderError, Message: "rendered output is syntactically invalid", Detail: "The rendered output is syntactically invalid. Ensure the rendered assignments are syntactically valid and review any use of `dangerously_bypass_escape`.", DocSlug: "docs/errors.md#eve-105-701"},
+	// B8: Value selection
+	"EVE-105-801": {Exit: ExitRenderError, Message: "entry has no line %d", Detail: "The `line=N` modifier selects a line past the end of the resolved value (after EOF newline normalization). Check the entry or correct N.", DocSlug: "docs/errors.md#eve-105-801"},
+	"EVE-105-802": {Exit: ExitRenderError, Message: "entry has no field %q", Detail: "The `field=NAME` modifier found no `NAME: value` line after the first line of the resolved value. Field names are compared case-insensitively. Add the field to the entry (e.g., `user: alice`) or correct the name.", DocSlug: "docs/errors.md#eve-105-802"},
+	// B9: Value decoding
+	"EVE-105-901": {Exit: ExitRenderError, Message: "value is not valid base64", Detail: "The `base64_decode` modifier expects standard Base64 (`A-Z`, `a-z`, `0-9`, `+`, `/`, optional `=` padding); SPACE, TAB and line breaks are ignored. Check that the stored value is Base64-encoded, or select the encoded part first (e.g., `first_line,base64_decode`).", DocSlug: "docs/errors.md#eve-105-901"},
+	"EVE-105-902": {Exit: ExitRenderError, Message: "decoded value contains NUL or invalid UTF-8", Detail: "The value decoded by `base64_decode` is binary and cannot be written to the .env file as text. Re-encode it by adding an encoding after `base64_decode` (e.g., `base64_decode,hex`), or store text instead.", DocSlug: "docs/errors.md#eve-105-902"},
+
+	// B10: Parameter expansion context
+	"EVE-105-1001": {Exit: ExitRenderError, Message: "newline not permitted in parameter expansion placeholder", Detail: "Newlines are not permitted in placeholders inside `${...}` unless the `allow_newline` modifier is present. Add `allow_newline` if the value is meant to span lines.", DocSlug: "docs/errors.md#eve-105-1001"},
+	"EVE-105-1002": {Exit: ExitRenderError, Message: "TAB not permitted in parameter expansion placeholder", Detail: "TAB is not permitted in placeholders inside `${...}` unless the `allow_tab` modifier is present. Add `allow_tab` if the value is meant to contain TAB.", DocSlug: "docs/errors.md#eve-105-1002"},
+	"EVE-105-1003": {Exit: ExitRenderError, Message: "control character U+%04X not permitted in parameter expansion placeholder", Detail: "Control characters are not supported in placeholders inside `${...}`. Use `$'...'` quoting as the default word (e.g., `${NAME:-$'<pass:path>'}`) or adjust the value.", DocSlug: "docs/errors.md#eve-105-1003"},
+	"EVE-105-1004": {Exit: ExitRenderError, Message: "single quote not permitted in double-quoted parameter expansion placeholder", Detail: "Inside `\"${...}\"`, bash keeps the backslash of `\\'` and pairs a bare `'`, so a single quote cannot be written. Drop the double quotes around the expansion (an assignment value is not word-split), e.g., `NAME=${NAME:-<pass:path>}`.", DocSlug: "docs/errors.md#eve-105-1004"},
 
 	// 106 Output (sync write: I/O)
 	"EVE-106-1":   {Exit: ExitOutputFailure, Message: "output directory %q does not exist", Detail: "The output directory does not exist. Create the directory before running `envseed`.", DocSlug: "docs/errors.md#eve-106-1"},
@@ -150,8 +225,16 @@ var errorRegistry = map[string]ErrorDetail{
 	"EVE-107-202": {Exit: ExitTargetParse, Message: "unterminated single quote in target .env", Detail: "A single‑quoted string is unterminated in the target `.env`. Close the string before the line ends. For example: NG: `NAME='value`.", DocSlug: "docs/errors.md#eve-107-202"},
 	"EVE-107-203": {Exit: ExitTargetParse, Message: "unterminated backtick substitution in target .env", Detail: "A backtick command substitution is unterminated in the target `.env`. Close the substitution. For example: NG: `` NAME=`cmd `.", DocSlug: "docs/errors.md#eve-107-203"},
 	"EVE-107-204": {Exit: ExitTargetParse, Message: "unterminated command substitution in target .env", Detail: "A `$()` command substitution is unterminated in the target `.env`. Ensure the opening and closing parentheses match. For example: NG: `NAME=$(cmd`.", DocSlug: "docs/errors.md#eve-107-204"},
+	"EVE-107-206": {Exit: ExitTargetParse, Message: "unterminated $'...' quote in target .env", Detail: "An ANSI-C `$'...'` string is unterminated in the target `.env`. Close the string; inside it, write a single quote as `\\'`. For example: NG: `NAME=$'value`.", DocSlug: "docs/errors.md#eve-107-206"},
+	"EVE-107-207": {Exit: ExitTargetParse, Message: "unterminated parameter expansion in target .env", Detail: "A `${...}` parameter expansion is unterminated in the target `.env`. Close it with `}`. For example: NG: `NAME=${HOST:-localhost`.", DocSlug: "docs/errors.md#eve-107-207"},
 	"EVE-107-205": {Exit: ExitTargetParse, Message: "invalid syntax in target .env", Detail: "The target `.env` contains invalid syntax. Ensure it follows the same grammar as the template, allowing assignments, comments, and blank lines only.", DocSlug: "docs/errors.md#eve-107-205"},
-	"EVE-107-301": {Exit: ExitTargetParse, Message: "placeholders are not allowed in target .env", Detail: "Placeholders are not allowed in the target `.env`. Remove constructs such as `<pass:...>`.", DocSlug: "docs/errors.md#eve-107-301"},
+	"EVE-107-301": {Exit: ExitTargetParse, Message: "placeholders are not allowed in target .env", Detail: "Placeholders are not allowed in the target `.env`. Remove constructs such as `<pass:...>` or `<env:...>`.", DocSlug: "docs/errors.md#eve-107-301"},
+	"EVE-107-401": {Exit: ExitTargetParse, Message: "value of %s at line %d cannot be imported", Detail: "`envseed import` found no placeholder that renders this value, typically because it contains control characters that no context accepts. Keep it literal with `--keep NAME`, or fix the value in the `.env`. Nothing was stored.", DocSlug: "docs/errors.md#eve-107-401"},
+	"EVE-107-402": {Exit: ExitTargetParse, Message: "conflicting values for %s at line %d", Detail: "`envseed import` stores each variable as one pass entry, but the variable is assigned different values. Remove the duplicate assignment or keep the variable literal with `--keep NAME`. Nothing was stored.", DocSlug: "docs/errors.md#eve-107-402"},
+	"EVE-107-501": {Exit: ExitTargetParse, Message: "%s at line %d is not a single placeholder", Detail: "`envseed push` can only write back a value whose template is exactly one placeholder, optionally quoted, such as `DB_PASSWORD=\"<pass:app/db>\"`. This value is literal in the template, or built from several placeholders or from placeholders and literal text, so it does not map to one entry. Update the entries yourself, or revert the change in the `.env`. Nothing was updated.", DocSlug: "docs/errors.md#eve-107-501"},
+	"EVE-107-502": {Exit: ExitTargetParse, Message: "placeholder of %s at line %d cannot be pushed", Detail: "`envseed push` only updates `<pass:...>` entries whose value is rendered unchanged. The placeholder uses another scheme, or a modifier that transforms or selects the value (such as `strip`, `base64`, `line=N`, `field=NAME` or `dangerously_bypass_escape`), so the entry cannot be derived from the `.env`. Update the entry yourself, or revert the change in the `.env`. Nothing was updated.", DocSlug: "docs/errors.md#eve-107-502"},
+	"EVE-107-503": {Exit: ExitTargetParse, Message: "value of %s at line %d cannot be pushed", Detail: "`envseed push` stores the value the shell would assign, and the next `sync` must render it with the same placeholder. The edited value uses `$`, backticks or a leading `~`, continues after unquoted whitespace, or contains characters the placeholder's context rejects (add `allow_newline`/`allow_tab` in the template when intended). Fix the value in the `.env`. Nothing was updated.", DocSlug: "docs/errors.md#eve-107-503"},
+	"EVE-107-504": {Exit: ExitTargetParse, Message: "conflicting pushed values for %s at line %d", Detail: "Two edited variables of the `.env` map to the same entry but hold different values, so it is unclear which one to store. Make the values equal or revert one of them. Nothing was updated.", DocSlug: "docs/errors.md#eve-107-504"},
 
 	// 108 Diff (comparison) — densified in B0
 	"EVE-108-1": {Exit: ExitDiffFailure, Message: "diff target %q exceeds 10 MiB size limit", Detail: "The target file exceeds the 10 MiB diff size limit. Reduce the file size or split the environment file before running `envseed diff`.", DocSlug: "docs/errors.md#eve-108-1"},
@@ -205,6 +288,17 @@ func (e *ExitError) Unwrap() error {
 	return e.Err
 }
 
+// MissingValue reports whether the error is a resolver missing-value failure,
+// which optional placeholders render as their fallback. Other EVE-104-B2
+// codes, such as an entry without an otpauth URI, report an entry that exists.
+func (e *ExitError) MissingValue() bool {
+	switch e.DetailCode {
+	case "EVE-104-201", "EVE-104-202", "EVE-104-203", "EVE-104-204", "EVE-104-205":
+		return true
+	}
+	return false
+}
+
 // WithErr returns a shallow copy of the error that wraps an additional error.
 func (e *ExitError) WithErr(err error) *ExitError {
 	if e == nil {
@@ -30,12 +30,17 @@ func wrapRenderError(err error) error {
 		return NewExitError(placeholderErr.DetailCode(), placeholderErr.DetailArgs()...).WithErr(err)
 	}
 
+	// Resolver failures are already ExitErrors; wrapping err again would
+	// repeat their message, detail and reference in the CLI output, so only
+	// the placeholder location is added to the message.
 	var exitErr *ExitError
 	if errors.As(err, &exitErr) {
-		clone := *exitErr
-		if clone.Err == nil {
-			clone.Err = err
+		var resolveErr *renderer.ResolveError
+		if !errors.As(err, &resolveErr) {
+			return exitErr
 		}
+		clone := *exitErr
+		clone.Msg = resolveErr.Location() + ": " + clone.Msg
 		return &clone
 	}
 	return NewExitError("EVE-105-1").WithErr(err)
@@ -0,0 +1,33 @@
+package envseed
+
+import (
+	"fmt"
+	"io"
+
+	"envseed/internal/renderer"
+)
+
+// fallbackNote describes a placeholder rendered from its fallback, e.g.
+// `line 4: SENTRY_DSN: <pass:sentry/dsn> not found; using default value`.
+// Placeholders of included templates are located as `FILE:LINE:COLUMN`.
+func fallbackNote(fb renderer.Fallback) string {
+	using := "empty value"
+	if fb.Default {
+		using = "default value"
+	}
+	location := fmt.Sprintf("line %d", fb.Line)
+	if fb.File != "" {
+		location = fmt.Sprintf("%s:%d:%d", fb.File, fb.Line, fb.Column)
+	}
+	return fmt.Sprintf("%s: %s: <%s:%s> not found; using %s", location, fb.Name, fb.Scheme, fb.Path, using)
+}
+
+// writeFallbacks writes one line per fallback, each starting with prefix.
+func writeFallbacks(w io.Writer, prefix string, fallbacks []renderer.Fallback) error {
+	for _, fb := range fallbacks {
+		if _, err := fmt.Fprintf(w, "%s%s\n", prefix, fallbackNote(fb)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package envseed
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+
+	"envseed/internal/ast"
+	"envseed/internal/renderer"
+)
+
+// Defaults for entries generated without `gen_len=` or `gen_charset=`.
+const (
+	defaultGenLength  = 32
+	defaultGenCharset = "alnum"
+)
+
+// genSpec is the length and character set of a generated entry.
+type genSpec struct {
+	length  int
+	charset string
+}
+
+// generatedEntry is a `pass` entry created by generateMissing.
+type generatedEntry struct {
+	path string
+	spec genSpec
+}
+
+// generateMissing creates the `<pass:...>` entries of elements that are
+// missing from the store; it runs after Prefetch so that missing entries are
+// already known. An entry is generated unless every placeholder of it is
+// optional (`optional` or `default=`) without `gen_len`/`gen_charset`. The
+// character set must render in the context of every placeholder of the entry;
+// selectors and base64_decode are not applied to it. Created values
+// are stored through the client's Inserter and served to rendering from the
+// cache. The entries created before a failure are returned with the error.
+func generateMissing(ctx context.Context, elements []ast.Element, resolver *secretResolver, client PassClient) ([]generatedEntry, error) {
+	var order []string
+	tokens := make(map[string][]ast.ValueToken)
+	for _, el := range elements {
+		if el.Type != ast.ElementAssignment || el.Assignment == nil {
+			continue
+		}
+		for _, tok := range el.Assignment.ValueTokens {
+			if tok.Kind != ast.ValuePlaceholder || tokenScheme(tok) != ast.SchemePass {
+				continue
+			}
+			if _, ok := tokens[tok.Path]; !ok {
+				order = append(order, tok.Path)
+			}
+			tokens[tok.Path] = append(tokens[tok.Path], tok)
+		}
+	}
+
+	var created []generatedEntry
+	for _, path := range order {
+		toks := tokens[path]
+		if !wantsGeneration(toks) {
+			continue
+		}
+		_, err := resolver.ResolveScheme(ast.SchemePass, path)
+		var exitErr *ExitError
+		if err == nil || !errors.As(err, &exitErr) || !exitErr.MissingValue() {
+			continue
+		}
+		spec, err := genSpecFor(path, toks)
+		if err != nil {
+			return created, err
+		}
+		for _, tok := range toks {
+			if err := renderer.CheckContext(tok, ast.GenCharsets[spec.charset]); err != nil {
+				return created, NewExitError("EVE-104-703", spec.charset, path, tok.Line).WithErr(err)
+			}
+		}
+		inserter, ok := client.(Inserter)
+		if !ok {
+			return created, NewExitError("EVE-104-701", "pass")
+		}
+		value, err := randomString(ast.GenCharsets[spec.charset], spec.length)
+		if err != nil {
+			return created, NewExitError("EVE-104-702", "pass", path).WithErr(err)
+		}
+		if err := inserter.Insert(ctx, path, value+"\n"); err != nil {
+			return created, err
+		}
+		resolver.cache.set(ast.SchemePass, path, value+"\n")
+		created = append(created, generatedEntry{path: path, spec: spec})
+	}
+	return created, nil
+}
+
+// wantsGeneration reports whether a missing entry with the placeholders toks
+// is generated: some placeholder is required or sets a gen_* modifier.
+func wantsGeneration(toks []ast.ValueToken) bool {
+	for _, tok := range toks {
+		optional := false
+		for _, m := range tok.Modifiers {
+			switch m.Name {
+			case "gen_len", "gen_charset":
+				return true
+			case "optional", "default":
+				optional = true
+			}
+		}
+		if !optional {
+			return true
+		}
+	}
+	return false
+}
+
+// genSpecFor returns the generation spec of path. Placeholders that set
+// gen_len or gen_charset must agree once defaults are applied.
+func genSpecFor(path string, toks []ast.ValueToken) (genSpec, error) {
+	spec := genSpec{length: defaultGenLength, charset: defaultGenCharset}
+	explicit := false
+	for _, tok := range toks {
+		s := genSpec{length: defaultGenLength, charset: defaultGenCharset}
+		set := false
+		for _, m := range tok.Modifiers {
+			switch m.Name {
+			case "gen_len":
+				s.length, _ = strconv.Atoi(m.Arg)
+				set = true
+			case "gen_charset":
+				s.charset = m.Arg
+				set = true
+			}
+		}
+		if !set {
+			continue
+		}
+		if explicit && s != spec {
+			return genSpec{}, NewExitError("EVE-104-704", path, tok.Line)
+		}
+		spec, explicit = s, true
+	}
+	return spec, nil
+}
+
+// randomString returns n characters drawn uniformly from charset.
+func randomString(charset string, n int) (string, error) {
+	limit := big.NewInt(int64(len(charset)))
+	out := make([]byte, n)
+	for i := range out {
+		k, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", err
+		}
+		out[i] = charset[k.Int64()]
+	}
+	return string(out), nil
+}
+
+// writeGenerated writes one line per created entry, e.g.
+// `generated: <pass:jwt/signing_key> (48 characters, alnum)`.
+func writeGenerated(w io.Writer, created []generatedEntry) error {
+	for _, g := range created {
+		if _, err := fmt.Fprintf(w, "generated: <pass:%s> (%d characters, %s)\n", g.path, g.spec.length, g.spec.charset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package envseed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"envseed/internal/ast"
+	"envseed/internal/testsupport"
+)
+
+// storePass is a writable fake store: missing entries report EVE-104-201,
+// Insert adds entries and Update replaces them, failing for the paths in fail.
+type storePass struct {
+	mu       sync.Mutex
+	values   map[string]string
+	inserted []string
+	updated  []string
+	fail     map[string]bool
+}
+
+func (s *storePass) Show(_ context.Context, path string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.values[path]; ok {
+		return v, nil
+	}
+	return "", NewExitError("EVE-104-201", "pass", path)
+}
+
+func (s *storePass) Insert(_ context.Context, path, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[path]; ok || s.fail[path] {
+		return NewExitError("EVE-104-702", "pass", path).WithErr(errors.New("store is read-only"))
+	}
+	if s.values == nil {
+		s.values = make(map[string]string)
+	}
+	s.values[path] = value
+	s.inserted = append(s.inserted, path)
+	return nil
+}
+
+func (s *storePass) Update(_ context.Context, path, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail[path] {
+		return NewExitError("EVE-104-707", "pass", path).WithErr(errors.New("store is read-only"))
+	}
+	if s.values == nil {
+		s.values = make(map[string]string)
+	}
+	s.values[path] = value
+	s.updated = append(s.updated, path)
+	return nil
+}
+
+// syncGenerate writes template and runs Sync with --generate-missing.
+func syncGenerate(t *testing.T, template string, client PassClient, quiet bool) (string, string, error) {
+	t.Helper()
+	input := filepath.Join(t.TempDir(), ".envseed")
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	var stderr bytes.Buffer
+	err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: client, GenerateMissing: true, Quiet: quiet, Stderr: &stderr})
+	data, _ := os.ReadFile(filepath.Join(filepath.Dir(input), ".env"))
+	return string(data), stderr.String(), err
+}
+
+// [EVT-MZU-20]
+func TestSyncGenerateMissingCreatesEntries(t *testing.T) {
+	store := &storePass{values: map[string]string{"db": "s3cret\n"}}
+	template := strings.Join([]string{
+		"DB=<pass:db>",
+		"JWT_KEY=<pass:jwt/signing_key|gen_len=48,gen_charset=hex>",
+		"JWT_KEY_B64=\"<pass:jwt/signing_key|base64>\"",
+		"SESSION=<pass:session/secret>",
+		"SENTRY_DSN=<pass:sentry/dsn|optional>",
+	}, "\n") + "\n"
+	out, stderr, err := syncGenerate(t, template, store, false)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if want := []string{"jwt/signing_key", "session/secret"}; !testsupport.EqualStrings(store.inserted, want) {
+		t.Fatalf("inserted = %v, want %v", store.inserted, want)
+	}
+	key := strings.TrimSuffix(store.values["jwt/signing_key"], "\n")
+	if len(key) != 48 || strings.Trim(key, ast.GenCharsets["hex"]) != "" {
+		t.Fatalf("jwt/signing_key = %q, want 48 hex characters", key)
+	}
+	session := strings.TrimSuffix(store.values["session/secret"], "\n")
+	if len(session) != defaultGenLength || strings.Trim(session, ast.GenCharsets["alnum"]) != "" {
+		t.Fatalf("session/secret = %q, want %d alnum characters", session, defaultGenLength)
+	}
+	if !strings.Contains(out, "JWT_KEY="+key+"\n") || !strings.Contains(out, "SESSION="+session+"\n") || !strings.Contains(out, "SENTRY_DSN=\n") {
+		t.Fatalf("output does not use the generated values: %q", out)
+	}
+	for _, want := range []string{
+		"generated: <pass:jwt/signing_key> (48 characters, hex)\n",
+		"generated: <pass:session/secret> (32 characters, alnum)\n",
+	} {
+		if !strings.Contains(stderr, want) {
+			t.Fatalf("stderr = %q, want it to contain %q", stderr, want)
+		}
+	}
+
+	_, stderr, err = syncGenerate(t, "NEW=<pass:new>\n", &storePass{}, true)
+	if err != nil || strings.Contains(stderr, "generated:") {
+		t.Fatalf("quiet: stderr = %q, err = %v", stderr, err)
+	}
+}
+
+// [EVT-MZU-20]
+func TestSyncGenerateMissingRejectsUnrenderableCharset(t *testing.T) {
+	for _, template := range []string{
+		"KEY='<pass:key|gen_charset=symbols>'\n",
+		"KEY=<pass:key|gen_charset=symbols,dangerously_bypass_escape>\nCOPY='<pass:key>'\n",
+	} {
+		store := &storePass{}
+		_, _, err := syncGenerate(t, template, store, false)
+		exitErr := expectExitDetail(t, err, "EVE-104-703")
+		if !strings.Contains(exitErr.Msg, "line ") || len(store.inserted) != 0 {
+			t.Fatalf("%q: err = %v, inserted = %v", template, exitErr, store.inserted)
+		}
+	}
+	if _, _, err := syncGenerate(t, "KEY=\"<pass:key|gen_charset=symbols>\"\n", &storePass{}, false); err != nil {
+		t.Fatalf("double-quoted symbols: Sync() error = %v", err)
+	}
+}
+
+// [EVT-MZU-20]
+func TestSyncGenerateMissingChecksContextOnly(t *testing.T) {
+	store := &storePass{}
+	out, _, err := syncGenerate(t, "KEY=<pass:key>\nRAW=<pass:key|base64_decode,base64>\nHEAD=\"<pass:key|line=1>\"\n", store, false)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	key := strings.TrimSuffix(store.values["key"], "\n")
+	if len(key) != defaultGenLength || out != "KEY="+key+"\nRAW="+key+"\nHEAD=\""+key+"\"\n" {
+		t.Fatalf("output = %q, generated %q", out, key)
+	}
+
+	// A selector that the generated value cannot satisfy is reported by
+	// rendering, not as a charset the context rejects.
+	store = &storePass{}
+	_, _, err = syncGenerate(t, "KEY=<pass:key>\nUSER=<pass:key|field=user>\n", store, false)
+	expectExitDetail(t, err, "EVE-105-802")
+	if len(store.inserted) != 1 {
+		t.Fatalf("inserted = %v, want [key]", store.inserted)
+	}
+}
+
+// [EVT-MZU-20]
+func TestSyncGenerateMissingErrors(t *testing.T) {
+	_, _, err := syncGenerate(t, "A=<pass:key|gen_len=16>\nB=<pass:key|gen_len=24>\n", &storePass{}, false)
+	expectExitDetail(t, err, "EVE-104-704")
+
+	_, _, err = syncGenerate(t, "A=<pass:key>\n", &fakePass{errs: map[string]error{"key": NewExitError("EVE-104-201", "pass", "key")}}, false)
+	expectExitDetail(t, err, "EVE-104-701")
+
+	store := &storePass{fail: map[string]bool{"b": true}}
+	_, stderr, err := syncGenerate(t, "A=<pass:a>\nB=<pass:b>\n", store, false)
+	expectExitDetail(t, err, "EVE-104-702")
+	if !strings.Contains(stderr, "generated: <pass:a> (32 characters, alnum)\n") {
+		t.Fatalf("stderr = %q, want the entry created before the failure", stderr)
+	}
+
+	input := filepath.Join(t.TempDir(), ".envseed")
+	if err := os.WriteFile(input, []byte("A=<pass:a>\n"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	err = Sync(context.Background(), SyncOptions{InputPath: input, PassClient: &storePass{}, GenerateMissing: true, DryRun: true})
+	expectExitDetail(t, err, "EVE-101-3")
+}
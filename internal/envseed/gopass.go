@@ -0,0 +1,90 @@
+package envseed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// gopassExitNotFound is the exit status gopass uses when an entry does not
+// exist (exit.NotFound in gopass). Other statuses, such as exit.Mount (8) for
+// a broken mount, are backend failures.
+const gopassExitNotFound = 10
+
+// GopassCommand implements PassClient using the gopass CLI. PATH is passed
+// through unchanged, so mount-prefixed paths such as `work/db/password` are
+// routed by gopass to the mounted store.
+type GopassCommand struct{}
+
+// Show retrieves PATH through gopass. The entry is printed verbatim
+// (`--noparsing`) so that the value matches what `pass show` would return.
+func (g *GopassCommand) Show(ctx context.Context, path string) (string, error) {
+	cmd := exec.CommandContext(ctx, "gopass", "show", "--noparsing", path)
+	// Connect stdin so that interactive pinentry can receive user input.
+	cmd.Stdin = os.Stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", NewExitError("EVE-104-1", "gopass").WithErr(err)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.ExitCode() == gopassExitNotFound || gopassEntryNotFound(stderr.String()) {
+				return "", NewExitError("EVE-104-201", "gopass", path).WithErr(err)
+			}
+			if name, ok := gopassMissingCommand(stderr.String()); ok {
+				return "", NewExitError("EVE-104-1", name).WithErr(err)
+			}
+		}
+		return "", NewExitError("EVE-104-101", "gopass", path).WithErr(err)
+	}
+	return string(out), nil
+}
+
+// Insert creates PATH through `gopass insert`, with value on stdin.
+func (g *GopassCommand) Insert(ctx context.Context, path, value string) error {
+	return storeWithCommand(ctx, "EVE-104-702", "gopass", []string{"insert", path}, path, value)
+}
+
+// Update replaces PATH through `gopass insert --force`.
+func (g *GopassCommand) Update(ctx context.Context, path, value string) error {
+	return storeWithCommand(ctx, "EVE-104-707", "gopass", []string{"insert", "--force", path}, path, value)
+}
+
+// gopassMissingCommand extracts NAME from the `exec: "NAME": executable file
+// not found` error gopass prints when a helper such as gpg is not installed.
+func gopassMissingCommand(msg string) (string, bool) {
+	const prefix = `exec: "`
+	i := strings.Index(msg, prefix)
+	if i < 0 {
+		return "", false
+	}
+	rest := msg[i+len(prefix):]
+	name, _, ok := strings.Cut(rest, `": executable file not found`)
+	if !ok || name == "" || strings.ContainsAny(name, "\"\n") {
+		return "", false
+	}
+	return name, true
+}
+
+// gopassEntryNotFound recognizes the gopass diagnostics for a missing entry,
+// including entries below a mount point. Other "not found" failures (a missing
+// gpg binary, mount or config file) are backend failures.
+func gopassEntryNotFound(msg string) bool {
+	m := strings.ToLower(msg)
+	patterns := []string{
+		"entry is not in the password store",
+		"secret not found",
+	}
+	for _, p := range patterns {
+		if strings.Contains(m, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package envseed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// installGopassStub writes a stub gopass script into an isolated PATH.
+func installGopassStub(t *testing.T, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "gopass"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+func gopassShow(t *testing.T, path string) (string, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return (&GopassCommand{}).Show(ctx, path)
+}
+
+// [EVT-MZU-9]
+func TestGopassCommandShowMountPrefixedPath(t *testing.T) {
+	installGopassStub(t, `#!/bin/sh
+if [ "$1" != "show" ] || [ "$2" != "--noparsing" ] || [ "$3" != "work/db/password" ]; then
+  echo "unexpected args: $*" >&2
+  exit 1
+fi
+printf 'secret\nuser: alice\n'
+`)
+	got, err := gopassShow(t, "work/db/password")
+	if err != nil {
+		t.Fatalf("Show error: %v", err)
+	}
+	if got != "secret\nuser: alice\n" {
+		t.Fatalf("Show = %q, want raw entry", got)
+	}
+}
+
+// [EVT-MZU-9]
+func TestGopassCommandShowErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"not found exit code", "#!/bin/sh\nexit 10\n", "EVE-104-201"},
+		{"mount exit code", "#!/bin/sh\necho \"Error: failed to mount work\" >&2\nexit 8\n", "EVE-104-101"},
+		{"missing gpg", "#!/bin/sh\necho 'Error: failed to decrypt: exec: \"gpg\": executable file not found in $PATH' >&2\nexit 1\n", "EVE-104-1"},
+		{"missing config", "#!/bin/sh\necho \"Error: config file not found\" >&2\nexit 1\n", "EVE-104-101"},
+		{"secret not found stderr", "#!/bin/sh\necho \"Error: secret not found\" >&2\nexit 1\n", "EVE-104-201"},
+		{"not found stderr", "#!/bin/sh\necho \"Error: failed to retrieve secret 'work/x': Entry is not in the password store\" >&2\nexit 1\n", "EVE-104-201"},
+		{"other failure", "#!/bin/sh\necho \"Error: failed to decrypt\" >&2\nexit 9\n", "EVE-104-101"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			installGopassStub(t, tc.script)
+			_, err := gopassShow(t, "work/x")
+			var exitErr *ExitError
+			if !errors.As(err, &exitErr) {
+				t.Fatalf("expected ExitError, got %v", err)
+			}
+			if exitErr.DetailCode != tc.want {
+				t.Fatalf("detail code = %s, want %s", exitErr.DetailCode, tc.want)
+			}
+		})
+	}
+}
+
+// [EVT-MZU-20]
+func TestGopassCommandInsert(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "inserted")
+	installGopassStub(t, `#!/bin/sh
+if [ "$1" != "insert" ] || [ "$2" != "work/jwt/key" ]; then
+  echo "unexpected args: $*" >&2
+  exit 1
+fi
+/bin/cat > "`+out+`"
+`)
+	if err := (&GopassCommand{}).Insert(context.Background(), "work/jwt/key", "tok3n\n"); err != nil {
+		t.Fatalf("Insert error: %v", err)
+	}
+	if data, err := os.ReadFile(out); err != nil || string(data) != "tok3n\n" {
+		t.Fatalf("stdin = %q, %v; want %q", data, err, "tok3n\n")
+	}
+	expectExitDetail(t, (&GopassCommand{}).Insert(context.Background(), "other", "x\n"), "EVE-104-702")
+}
+
+// [EVT-MZU-22]
+func TestGopassCommandUpdate(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "updated")
+	installGopassStub(t, `#!/bin/sh
+if [ "$1 $2 $3" != "insert --force work/jwt/key" ]; then
+  echo "unexpected args: $*" >&2
+  exit 1
+fi
+/bin/cat > "`+out+`"
+`)
+	if err := (&GopassCommand{}).Update(context.Background(), "work/jwt/key", "n3w\n"); err != nil {
+		t.Fatalf("Update error: %v", err)
+	}
+	if data, err := os.ReadFile(out); err != nil || string(data) != "n3w\n" {
+		t.Fatalf("stdin = %q, %v; want %q", data, err, "n3w\n")
+	}
+	expectExitDetail(t, (&GopassCommand{}).Update(context.Background(), "other", "x\n"), "EVE-104-707")
+}
+
+// [EVT-MZU-9]
+func TestGopassCommandShowMissingBinary(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	_, err := gopassShow(t, "any/path")
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.DetailCode != "EVE-104-1" {
+		t.Fatalf("expected EVE-104-1, got %v", err)
+	}
+	if !errors.Is(err, exec.ErrNotFound) {
+		t.Fatalf("error does not wrap exec.ErrNotFound: %v", err)
+	}
+}
+
+// [EVT-MZU-9]
+func TestNewPassClientBackends(t *testing.T) {
+	for backend, want := range map[string]PassClient{
+		"":       &PassCommand{},
+		"pass":   &PassCommand{},
+		"gopass": &GopassCommand{},
+	} {
+		got, err := NewPassClient(backend)
+		if err != nil {
+			t.Fatalf("NewPassClient(%q) error: %v", backend, err)
+		}
+		if fmt.Sprintf("%T", got) != fmt.Sprintf("%T", want) {
+			t.Fatalf("NewPassClient(%q) = %T, want %T", backend, got, want)
+		}
+	}
+	_, err := NewPassClient("keepass")
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.DetailCode != "EVE-101-7" {
+		t.Fatalf("expected EVE-101-7, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"envseed/internal/parser"
 	"envseed/internal/renderer"
@@ -12,12 +13,15 @@ import (
 // Shared test helpers extracted from core_test.go for reuse across split files.
 
 type fakePass struct {
+	mu     sync.Mutex
 	values map[string]string
 	errs   map[string]error
 	calls  map[string]int
 }
 
 func (f *fakePass) Show(ctx context.Context, path string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	if f.calls == nil {
 		f.calls = make(map[string]int)
 	}
@@ -0,0 +1,434 @@
+package envseed
+
+// Import (`envseed import`): turns an existing .env into a template plus pass
+// entries. This file holds:
+//  - Import: plans every assignment, stores the values, writes the template
+//  - decodeEnvValue: shell decoding of one .env value into quoted segments
+//  - importPlaceholder: the placeholder that renders a value in its context
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"envseed/internal/ast"
+	"envseed/internal/parser"
+	"envseed/internal/renderer"
+)
+
+// envSegment is one quoted or unquoted part of a .env value, decoded.
+type envSegment struct {
+	context ast.ValueContext
+	text    string
+}
+
+// importedEntry is a pass entry created by Import.
+type importedEntry struct {
+	path  string
+	value string
+}
+
+// Import parses the .env at opts.InputPath with ParseTarget and writes a
+// template that keeps its comments, blank lines, order and quoting, with each
+// value replaced by a `<pass:PREFIX/NAME>` placeholder whose entry holds the
+// decoded value. Values matching a keep pattern, empty values and values the
+// shell would expand stay literal. Every value is checked, and every entry
+// must not exist yet, before any entry is created; the template is written
+// after all entries are stored.
+func Import(ctx context.Context, opts ImportOptions) error {
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	prefix, ok := cleanPassPrefix(opts.Prefix)
+	if !ok {
+		return NewExitError("EVE-101-10", opts.Prefix)
+	}
+	for _, pattern := range opts.Keep {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return NewExitError("EVE-101-11", pattern).WithErr(err)
+		}
+	}
+
+	if info, statErr := os.Lstat(opts.InputPath); statErr != nil {
+		code := classifyStatDetail(statErr)
+		return NewExitError(code, opts.InputPath).WithErr(statErr)
+	} else if info.IsDir() {
+		return NewExitError("EVE-102-2", opts.InputPath)
+	}
+	data, err := os.ReadFile(opts.InputPath)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return NewExitError("EVE-102-101", opts.InputPath).WithErr(err)
+		}
+		return NewExitError("EVE-102-202", opts.InputPath).WithErr(err)
+	}
+	elements, err := ParseTarget(string(data))
+	if err != nil {
+		return err
+	}
+
+	outputPath := opts.OutputPath
+	if outputPath == "" {
+		outputPath = filepath.Join(filepath.Dir(opts.InputPath), ".envseed")
+	}
+
+	var b strings.Builder
+	var entries []importedEntry
+	stored := make(map[string]string)
+	var warnings []string
+	for _, el := range elements {
+		switch el.Type {
+		case ast.ElementBlank:
+			b.WriteString(el.Text)
+			continue
+		case ast.ElementComment:
+			b.WriteString(el.Text)
+			if el.HasTrailingNewline {
+				b.WriteString("\n")
+			}
+			continue
+		}
+		as := el.Assignment
+		if keepLiteral(as.Name, opts.Keep) {
+			b.WriteString(as.Raw)
+			continue
+		}
+		segs, trailing, reason := decodeEnvValue(valueText(as.ValueTokens))
+		if reason != "" {
+			warnings = append(warnings, fmt.Sprintf("warning: line %d: %s: value kept literal (%s)", as.Line, as.Name, reason))
+			b.WriteString(as.Raw)
+			continue
+		}
+		value := joinSegments(segs)
+		if value == "" {
+			b.WriteString(as.Raw)
+			continue
+		}
+		entryPath := prefix + "/" + as.Name
+		placeholder, err := importPlaceholder(as.Name, entryPath, segs, value)
+		if err != nil {
+			return NewExitError("EVE-107-401", as.Name, as.Line).WithErr(err)
+		}
+		if prev, ok := stored[entryPath]; ok {
+			if prev != value {
+				return NewExitError("EVE-107-402", as.Name, as.Line)
+			}
+		} else {
+			stored[entryPath] = value
+			entries = append(entries, importedEntry{path: entryPath, value: value})
+		}
+		b.WriteString(as.LeadingWhitespace)
+		b.WriteString(as.Declaration)
+		b.WriteString(as.Name)
+		if as.Operator == ast.OperatorAppend {
+			b.WriteString("+=")
+		} else {
+			b.WriteString("=")
+		}
+		b.WriteString(placeholder)
+		b.WriteString(trailing)
+		b.WriteString(as.TrailingComment)
+		if as.HasTrailingNewline {
+			b.WriteString("\n")
+		}
+	}
+	template := b.String()
+
+	if err := validateOutputPath(outputPath); err != nil {
+		return err
+	}
+	if existing, err := readFileIfExists(outputPath); err != nil {
+		return err
+	} else if existing != nil && string(existing) != template && !opts.Force {
+		return NewExitError("EVE-106-101", outputPath)
+	}
+
+	if !opts.Quiet {
+		for _, w := range warnings {
+			fmt.Fprintln(stderr, w)
+		}
+	}
+	if len(entries) > 0 {
+		passClient := opts.PassClient
+		if passClient == nil {
+			passClient = &PassCommand{}
+		}
+		inserter, ok := passClient.(Inserter)
+		if !ok {
+			return NewExitError("EVE-104-701", "pass")
+		}
+		for _, entry := range entries {
+			_, err := passClient.Show(ctx, entry.path)
+			var exitErr *ExitError
+			if err == nil {
+				return NewExitError("EVE-104-705", "pass", entry.path)
+			} else if !errors.As(err, &exitErr) || !exitErr.MissingValue() {
+				return err
+			}
+		}
+		for _, entry := range entries {
+			if err := inserter.Insert(ctx, entry.path, entry.value+"\n"); err != nil {
+				return err
+			}
+			if !opts.Quiet {
+				fmt.Fprintf(stderr, "stored: <pass:%s>\n", entry.path)
+			}
+		}
+	}
+	return writeOutput(outputPath, []byte(template), opts.Quiet, opts.Force, stderr)
+}
+
+// cleanPassPrefix validates a pass folder such as `myapp/dev` and returns it
+// without surrounding slashes. Segments must be non-empty, not `.` or `..`,
+// and free of whitespace, control characters and the placeholder separators.
+func cleanPassPrefix(prefix string) (string, bool) {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return "", false
+	}
+	for _, seg := range strings.Split(prefix, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return "", false
+		}
+	}
+	if strings.ContainsAny(prefix, "|<>") || strings.ContainsFunc(prefix, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}) {
+		return "", false
+	}
+	return prefix, true
+}
+
+// keepLiteral reports whether name matches one of the keep patterns.
+func keepLiteral(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeEnvValue decodes the raw value text of a .env assignment the way the
+// shell assigns it: quotes are removed, backslash escapes are resolved
+// (outside single quotes, ANSI-C style inside `$'...'`) and line
+// continuations are dropped. It returns the
+// decoded segments and the whitespace after the value, or a reason when the
+// value is not a plain word: it expands a variable, a command or `~`, or
+// continues after unquoted whitespace.
+func decodeEnvValue(raw string) ([]envSegment, string, string) {
+	const expansion = "uses expansion or command substitution"
+	var segs []envSegment
+	add := func(ctx ast.ValueContext, text string) {
+		if n := len(segs); n > 0 && segs[n-1].context == ctx {
+			segs[n-1].text += text
+			return
+		}
+		segs = append(segs, envSegment{context: ctx, text: text})
+	}
+	for i := 0; i < len(raw); {
+		switch c := raw[i]; {
+		case c == ' ' || c == '\t':
+			if strings.TrimLeft(raw[i:], " \t") != "" {
+				return nil, "", "contains unquoted whitespace"
+			}
+			return segs, raw[i:], ""
+		case c == '\'':
+			end := strings.IndexByte(raw[i+1:], '\'')
+			if end < 0 {
+				return nil, "", "unterminated quote"
+			}
+			add(ast.ContextSingleQuoted, raw[i+1:i+1+end])
+			i += end + 2
+		case c == '"':
+			var b strings.Builder
+			i++
+			for ; i < len(raw) && raw[i] != '"'; i++ {
+				switch raw[i] {
+				case '\\':
+					if i+1 < len(raw) {
+						switch raw[i+1] {
+						case '$', '`', '"', '\\':
+							b.WriteByte(raw[i+1])
+							i++
+							continue
+						case '\n':
+							i++
+							continue
+						}
+					}
+					b.WriteByte('\\')
+				case '$', '`':
+					return nil, "", expansion
+				default:
+					b.WriteByte(raw[i])
+				}
+			}
+			if i >= len(raw) {
+				return nil, "", "unterminated quote"
+			}
+			i++
+			add(ast.ContextDoubleQuoted, b.String())
+		case c == '\\':
+			switch {
+			case i+1 >= len(raw):
+				add(ast.ContextBare, "\\")
+				i++
+			case raw[i+1] == '\n':
+				i += 2
+			default:
+				add(ast.ContextBare, raw[i+1:i+2])
+				i += 2
+			}
+		case c == '$' && i+1 < len(raw) && raw[i+1] == '\'':
+			text, n, reason := decodeDollarSingleQuoted(raw[i+2:])
+			if reason != "" {
+				return nil, "", reason
+			}
+			add(ast.ContextDollarSingleQuoted, text)
+			i += n + 3
+		case c == '$' || c == '`' || (c == '~' && i == 0):
+			return nil, "", expansion
+		default:
+			add(ast.ContextBare, raw[i:i+1])
+			i++
+		}
+	}
+	return segs, "", ""
+}
+
+// decodeDollarSingleQuoted decodes the body of an ANSI-C `$'...'` string, the
+// text after `$'`, up to the closing quote. It returns the decoded text and
+// the length of the body, or a reason for escapes it does not decode (`\c`,
+// `\u`, `\U`) and for NUL, at which the shell would cut the value.
+func decodeDollarSingleQuoted(body string) (string, int, string) {
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c == '\'' {
+			if strings.IndexByte(b.String(), 0) >= 0 {
+				return "", 0, "contains NUL"
+			}
+			return b.String(), i, ""
+		}
+		if c != '\\' || i+1 >= len(body) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch e := body[i]; e {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'v':
+			b.WriteByte('\v')
+		case 'f':
+			b.WriteByte('\f')
+		case 'e', 'E':
+			b.WriteByte(0x1b)
+		case '\\', '\'', '"', '?':
+			b.WriteByte(e)
+		case 'x':
+			n := 0
+			for n < 2 && i+1+n < len(body) && strings.IndexByte("0123456789abcdefABCDEF", body[i+1+n]) >= 0 {
+				n++
+			}
+			if n == 0 {
+				b.WriteString(`\x`)
+				continue
+			}
+			v, _ := strconv.ParseUint(body[i+1:i+1+n], 16, 8)
+			b.WriteByte(byte(v))
+			i += n
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			n := 1
+			for n < 3 && i+n < len(body) && body[i+n] >= '0' && body[i+n] <= '7' {
+				n++
+			}
+			v, _ := strconv.ParseUint(body[i:i+n], 8, 16)
+			b.WriteByte(byte(v))
+			i += n - 1
+		case 'c', 'u', 'U':
+			return "", 0, "uses an unsupported $'...' escape"
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(e)
+		}
+	}
+	return "", 0, "unterminated quote"
+}
+
+func joinSegments(segs []envSegment) string {
+	var b strings.Builder
+	for _, s := range segs {
+		b.WriteString(s.text)
+	}
+	return b.String()
+}
+
+// importPlaceholder returns the quoted `<pass:PATH>` placeholder that renders
+// value. It keeps the quoting of the original value; values mixing quoting
+// styles, and newlines or TABs that the original context cannot render, move
+// to double quotes with `allow_newline`/`allow_tab`. `$'...'` renders any
+// value and needs neither. The placeholder is
+// checked with renderer.CheckValue.
+func importPlaceholder(name, entryPath string, segs []envSegment, value string) (string, error) {
+	valueCtx := segs[0].context
+	for _, s := range segs[1:] {
+		if s.context != valueCtx {
+			valueCtx = ast.ContextDoubleQuoted
+		}
+	}
+	var mods []string
+	if valueCtx != ast.ContextDollarSingleQuoted && strings.ContainsAny(value, "\n\r") {
+		valueCtx = ast.ContextDoubleQuoted
+		mods = append(mods, "allow_newline")
+	}
+	if valueCtx != ast.ContextDollarSingleQuoted && strings.Contains(value, "\t") {
+		if valueCtx == ast.ContextBare {
+			valueCtx = ast.ContextDoubleQuoted
+		}
+		mods = append(mods, "allow_tab")
+	}
+	placeholder := "<" + ast.SchemePass + ":" + entryPath
+	if len(mods) > 0 {
+		placeholder += "|" + strings.Join(mods, ",")
+	}
+	placeholder += ">"
+	switch valueCtx {
+	case ast.ContextDoubleQuoted:
+		placeholder = `"` + placeholder + `"`
+	case ast.ContextSingleQuoted:
+		placeholder = "'" + placeholder + "'"
+	case ast.ContextDollarSingleQuoted:
+		placeholder = "$'" + placeholder + "'"
+	}
+
+	elems, err := parser.Parse(name + "=" + placeholder + "\n")
+	if err != nil {
+		return "", err
+	}
+	for _, tok := range elems[0].Assignment.ValueTokens {
+		if tok.Kind == ast.ValuePlaceholder {
+			if err := renderer.CheckValue(tok, value+"\n"); err != nil {
+				return "", err
+			}
+			return placeholder, nil
+		}
+	}
+	return "", fmt.Errorf("no placeholder in %q", placeholder)
+}
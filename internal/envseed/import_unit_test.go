@@ -0,0 +1,229 @@
+package envseed
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"envseed/internal/ast"
+	"envseed/internal/testsupport"
+)
+
+const importEnv = `# database
+DB_PASSWORD=p@ss\ word
+API_KEY="a\$b\"c" # rotated monthly
+
+  TOKEN='x"y'
+CERT="line1
+line2"
+TABBED="a	b"
+MIX='a b'"c"
+NODE_ENV=production
+HOME_DIR="$HOME/app"
+EMPTY=
+PATHS+=/opt/bin
+ANSI=$'it\'s\x01\tok'
+`
+
+const importTemplate = `# database
+DB_PASSWORD=<pass:myapp/dev/DB_PASSWORD>
+API_KEY="<pass:myapp/dev/API_KEY>" # rotated monthly
+
+  TOKEN='<pass:myapp/dev/TOKEN>'
+CERT="<pass:myapp/dev/CERT|allow_newline>"
+TABBED="<pass:myapp/dev/TABBED|allow_tab>"
+MIX="<pass:myapp/dev/MIX>"
+NODE_ENV=production
+HOME_DIR="$HOME/app"
+EMPTY=
+PATHS+=<pass:myapp/dev/PATHS>
+ANSI=$'<pass:myapp/dev/ANSI>'
+`
+
+// importFile writes content as .env in a new directory and returns its path.
+func importFile(t *testing.T, content string) string {
+	t.Helper()
+	input := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatalf("write .env: %v", err)
+	}
+	return input
+}
+
+// envValues decodes every assignment of a .env text by name.
+func envValues(t *testing.T, text string) map[string]string {
+	t.Helper()
+	elems, err := ParseTarget(text)
+	if err != nil {
+		t.Fatalf("ParseTarget: %v", err)
+	}
+	values := make(map[string]string)
+	for _, el := range elems {
+		if el.Type != ast.ElementAssignment {
+			continue
+		}
+		segs, _, reason := decodeEnvValue(valueText(el.Assignment.ValueTokens))
+		if reason != "" {
+			values[el.Assignment.Name] = "literal:" + valueText(el.Assignment.ValueTokens)
+			continue
+		}
+		values[el.Assignment.Name] = joinSegments(segs)
+	}
+	return values
+}
+
+// [EVT-MZU-21]
+func TestImportWritesTemplateAndStoresValues(t *testing.T) {
+	input := importFile(t, importEnv)
+	store := &storePass{}
+	var stderr bytes.Buffer
+	err := Import(context.Background(), ImportOptions{InputPath: input, Prefix: "myapp/dev/", Keep: []string{"NODE_*"}, PassClient: store, Stderr: &stderr})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	templatePath := filepath.Join(filepath.Dir(input), ".envseed")
+	data, err := os.ReadFile(templatePath)
+	if err != nil {
+		t.Fatalf("read template: %v", err)
+	}
+	if string(data) != importTemplate {
+		t.Fatalf("template = %q, want %q", data, importTemplate)
+	}
+	want := map[string]string{
+		"DB_PASSWORD": "p@ss word",
+		"API_KEY":     "a$b\"c",
+		"TOKEN":       "x\"y",
+		"CERT":        "line1\nline2",
+		"TABBED":      "a\tb",
+		"MIX":         "a bc",
+		"PATHS":       "/opt/bin",
+		"ANSI":        "it's\x01\tok",
+	}
+	for name, value := range want {
+		if got := store.values["myapp/dev/"+name]; got != value+"\n" {
+			t.Fatalf("entry %s = %q, want %q", name, got, value+"\n")
+		}
+	}
+	wantOrder := []string{"myapp/dev/DB_PASSWORD", "myapp/dev/API_KEY", "myapp/dev/TOKEN", "myapp/dev/CERT", "myapp/dev/TABBED", "myapp/dev/MIX", "myapp/dev/PATHS", "myapp/dev/ANSI"}
+	if !testsupport.EqualStrings(store.inserted, wantOrder) {
+		t.Fatalf("inserted = %v, want %v", store.inserted, wantOrder)
+	}
+	for _, line := range []string{
+		"warning: line 11: HOME_DIR: value kept literal (uses expansion or command substitution)\n",
+		"stored: <pass:myapp/dev/DB_PASSWORD>\n",
+		"wrote " + templatePath + " (mode 0600)\n",
+	} {
+		if !strings.Contains(stderr.String(), line) {
+			t.Fatalf("stderr = %q, want it to contain %q", stderr.String(), line)
+		}
+	}
+}
+
+// [EVT-MZU-21]
+func TestImportRoundTripsThroughSync(t *testing.T) {
+	input := importFile(t, importEnv)
+	store := &storePass{}
+	templatePath := filepath.Join(t.TempDir(), "app.envseed")
+	if err := Import(context.Background(), ImportOptions{InputPath: input, OutputPath: templatePath, Prefix: "myapp/dev", PassClient: store, Quiet: true}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	output := filepath.Join(t.TempDir(), ".env")
+	if err := Sync(context.Background(), SyncOptions{InputPath: templatePath, OutputPath: output, PassClient: store, Quiet: true}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	rendered, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	got, want := envValues(t, string(rendered)), envValues(t, importEnv)
+	for name, value := range want {
+		if got[name] != value {
+			t.Fatalf("%s = %q after sync, want %q", name, got[name], value)
+		}
+	}
+}
+
+// [EVT-MZU-21]
+func TestImportKeepsEverythingLiteral(t *testing.T) {
+	input := importFile(t, importEnv)
+	store := &storePass{}
+	if err := Import(context.Background(), ImportOptions{InputPath: input, Prefix: "p", Keep: []string{"*"}, PassClient: store, Quiet: true}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(input), ".envseed"))
+	if err != nil || string(data) != importEnv || len(store.inserted) != 0 {
+		t.Fatalf("template = %q, %v; inserted = %v", data, err, store.inserted)
+	}
+}
+
+// [EVT-MZU-21]
+func TestImportErrors(t *testing.T) {
+	input := importFile(t, "A=1\n")
+	for prefix, code := range map[string]string{
+		"":          "EVE-101-10",
+		"/":         "EVE-101-10",
+		"a//b":      "EVE-101-10",
+		"../x":      "EVE-101-10",
+		"my app":    "EVE-101-10",
+		"a|b":       "EVE-101-10",
+		"myapp/dev": "",
+	} {
+		err := Import(context.Background(), ImportOptions{InputPath: input, OutputPath: filepath.Join(t.TempDir(), ".envseed"), Prefix: prefix, PassClient: &storePass{}, Quiet: true})
+		if code == "" {
+			if err != nil {
+				t.Fatalf("prefix %q: Import() error = %v", prefix, err)
+			}
+			continue
+		}
+		expectExitDetail(t, err, code)
+	}
+	err := Import(context.Background(), ImportOptions{InputPath: input, Prefix: "p", Keep: []string{"[A"}, PassClient: &storePass{}})
+	expectExitDetail(t, err, "EVE-101-11")
+
+	cases := map[string]string{
+		"A=1\nA=2\n":       "EVE-107-402",
+		"A=\"x\x01y\"\n":   "EVE-107-401",
+		"A=<pass:x>\n":     "EVE-107-301",
+		"A=\"unterminated": "EVE-107-201",
+	}
+	for content, code := range cases {
+		store := &storePass{}
+		err := Import(context.Background(), ImportOptions{InputPath: importFile(t, content), Prefix: "p", PassClient: store, Quiet: true})
+		expectExitDetail(t, err, code)
+		if len(store.inserted) != 0 {
+			t.Fatalf("%q: inserted %v before failing", content, store.inserted)
+		}
+	}
+	if err := Import(context.Background(), ImportOptions{InputPath: importFile(t, "A=1\nA=1\n"), Prefix: "p", PassClient: &storePass{}, Quiet: true}); err != nil {
+		t.Fatalf("repeated identical value: Import() error = %v", err)
+	}
+
+	existing := importFile(t, "A=1\n")
+	if err := os.WriteFile(filepath.Join(filepath.Dir(existing), ".envseed"), []byte("OLD=1\n"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	store := &storePass{}
+	err = Import(context.Background(), ImportOptions{InputPath: existing, Prefix: "p", PassClient: store, Quiet: true})
+	expectExitDetail(t, err, "EVE-106-101")
+	if len(store.inserted) != 0 {
+		t.Fatalf("inserted %v before refusing to overwrite", store.inserted)
+	}
+	if err := Import(context.Background(), ImportOptions{InputPath: existing, Prefix: "p", Force: true, PassClient: store, Quiet: true}); err != nil {
+		t.Fatalf("--force: Import() error = %v", err)
+	}
+
+	err = Import(context.Background(), ImportOptions{InputPath: importFile(t, "A=1\n"), Prefix: "p", PassClient: &fakePass{}, Quiet: true})
+	expectExitDetail(t, err, "EVE-104-701")
+	store = &storePass{values: map[string]string{"p/B": "old\n"}}
+	err = Import(context.Background(), ImportOptions{InputPath: importFile(t, "A=1\nB=2\n"), Prefix: "p", PassClient: store, Quiet: true})
+	expectExitDetail(t, err, "EVE-104-705")
+	if len(store.inserted) != 0 {
+		t.Fatalf("inserted %v before finding the existing entry", store.inserted)
+	}
+	store = &storePass{fail: map[string]bool{"p/A": true}}
+	err = Import(context.Background(), ImportOptions{InputPath: importFile(t, "A=1\n"), Prefix: "p", PassClient: store, Quiet: true})
+	expectExitDetail(t, err, "EVE-104-702")
+}
@@ -0,0 +1,95 @@
+//go:build integration
+// +build integration
+
+package envseed_integration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	envseedpkg "envseed/internal/envseed"
+)
+
+// [EVT-MZU-10] native store against real gpg: one batch, raw values, not-found mapping
+func TestPassStoreBatchDecryptIntegration(t *testing.T) {
+	requireCommand(t, "gpg")
+
+	base := t.TempDir()
+	gpgHome := filepath.Join(base, "gnupg")
+	if err := os.Mkdir(gpgHome, 0o700); err != nil {
+		t.Fatalf("mkdir gnupg: %v", err)
+	}
+	storeDir := filepath.Join(base, "password-store")
+	t.Setenv("GNUPGHOME", gpgHome)
+	t.Setenv("PASSWORD_STORE_GPG_OPTS", "")
+
+	gen := exec.Command("gpg", "--batch", "--pinentry-mode", "loopback", "--passphrase", "", "--quick-gen-key", "Envseed Store <store@example.com>", "default", "default", "1d")
+	if out, err := gen.CombinedOutput(); err != nil {
+		t.Skipf("skipping: gpg --quick-gen-key failed: %v\n%s", err, out)
+	}
+	fprOut, err := exec.Command("gpg", "--batch", "--with-colons", "--list-secret-keys").CombinedOutput()
+	if err != nil {
+		t.Fatalf("gpg --list-secret-keys failed: %v\n%s", err, fprOut)
+	}
+	fingerprint := extractFingerprint(string(fprOut))
+
+	secrets := map[string]string{
+		"service/token":     "super-secret-value\n",
+		"service/multiline": "line1\nline2",
+		"team/empty":        "",
+	}
+	if err := os.MkdirAll(filepath.Join(storeDir, "team"), 0o700); err != nil {
+		t.Fatalf("mkdir store: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(storeDir, ".gpg-id"), []byte(fingerprint+"\n"), 0o600); err != nil {
+		t.Fatalf("write .gpg-id: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(storeDir, "team", ".gpg-id"), []byte("store@example.com\n"), 0o600); err != nil {
+		t.Fatalf("write team .gpg-id: %v", err)
+	}
+	for path, value := range secrets {
+		file := filepath.Join(storeDir, filepath.FromSlash(path)+".gpg")
+		if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		enc := exec.Command("gpg", "--batch", "--yes", "--trust-model", "always", "-e", "-r", fingerprint, "-o", file)
+		enc.Stdin = bytes.NewBufferString(value)
+		if out, err := enc.CombinedOutput(); err != nil {
+			t.Fatalf("gpg encrypt %s: %v\n%s", path, err, out)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	store := &envseedpkg.PassStore{Dir: storeDir}
+	store.Prefetch(ctx, []string{"service/token", "service/multiline", "team/empty", "missing/entry"})
+	for path, want := range secrets {
+		got, err := store.Show(ctx, path)
+		if err != nil {
+			t.Fatalf("Show(%q) error = %v", path, err)
+		}
+		if got != want {
+			t.Fatalf("Show(%q) = %q, want %q", path, got, want)
+		}
+	}
+	_, err = store.Show(ctx, "missing/entry")
+	var exitErr *envseedpkg.ExitError
+	if !errors.As(err, &exitErr) || exitErr.DetailCode != "EVE-104-201" {
+		t.Fatalf("Show(missing/entry) error = %v, want EVE-104-201", err)
+	}
+	if entries, _ := os.ReadDir(storeDir); len(entries) != 3 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Fatalf("store directory changed: %s", strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,92 @@
+package envseed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// OnePasswordCommand implements SchemeClient for `<op:VAULT/ITEM/FIELD>`
+// placeholders using the 1Password CLI. PATH is the secret reference without
+// its `op://` prefix and is read with `op read`.
+type OnePasswordCommand struct{}
+
+// Show retrieves the secret reference `op://PATH`.
+func (o *OnePasswordCommand) Show(ctx context.Context, path string) (string, error) {
+	cmd := exec.CommandContext(ctx, "op", "read", "op://"+path)
+	// Connect stdin so that interactive sign-in can receive user input.
+	cmd.Stdin = os.Stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", NewExitError("EVE-104-1", "op").WithErr(err)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			errMsg := stderr.String()
+			if msg := strings.TrimSpace(errMsg); msg != "" {
+				err = errors.New(msg)
+			}
+			switch {
+			case opSessionExpired(errMsg):
+				return "", NewExitError("EVE-104-503", "op").WithErr(err)
+			case opVaultLocked(errMsg):
+				return "", NewExitError("EVE-104-502", "op").WithErr(err)
+			case opEntryNotFound(errMsg):
+				return "", NewExitError("EVE-104-201", "op", path).WithErr(err)
+			}
+		}
+		return "", NewExitError("EVE-104-106", "op", path).WithErr(err)
+	}
+	return string(out), nil
+}
+
+// opSessionExpired recognizes op diagnostics for a missing or expired sign-in.
+func opSessionExpired(msg string) bool {
+	return containsAnyFold(msg,
+		"session expired",
+		"not currently signed in",
+		"you are not signed in",
+		"account is not signed in",
+	)
+}
+
+// opVaultLocked recognizes op diagnostics for a locked 1Password app
+// (desktop app integration).
+func opVaultLocked(msg string) bool {
+	return containsAnyFold(msg,
+		"1password is locked",
+		"app is locked",
+		"account is locked",
+	)
+}
+
+// opEntryNotFound recognizes op diagnostics for a reference whose vault, item,
+// or field does not exist.
+func opEntryNotFound(msg string) bool {
+	return containsAnyFold(msg,
+		"isn't a vault",
+		"isn't an item",
+		"isn't a field",
+		"no item found",
+		"could not find",
+		"not found",
+	)
+}
+
+// containsAnyFold reports whether msg contains any of the lower-case patterns,
+// ignoring case.
+func containsAnyFold(msg string, patterns ...string) bool {
+	m := strings.ToLower(msg)
+	for _, p := range patterns {
+		if strings.Contains(m, p) {
+			return true
+		}
+	}
+	return false
+}
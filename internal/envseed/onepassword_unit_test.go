@@ -0,0 +1,58 @@
+package envseed
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// [EVT-MZU-14]
+func TestOnePasswordCommandReadsReference(t *testing.T) {
+	installCommandStub(t, "op", `#!/bin/sh
+if [ "$1" != "read" ] || [ "$2" != "op://Private/GitHub/token" ]; then
+  echo "unexpected args: $*" >&2
+  exit 1
+fi
+printf 'ghp_secret\n'
+`)
+	got, err := (&OnePasswordCommand{}).Show(context.Background(), "Private/GitHub/token")
+	if err != nil {
+		t.Fatalf("Show error: %v", err)
+	}
+	if got != "ghp_secret\n" {
+		t.Fatalf("Show = %q, want raw output", got)
+	}
+}
+
+// [EVT-MZU-14][EVT-MZU-2]
+func TestOnePasswordCommandClassifiesStderr(t *testing.T) {
+	cases := []struct {
+		name   string
+		stderr string
+		want   string
+	}{
+		{"missing item", `[ERROR] 2024/05/01 10:00:00 could not read secret 'op://Private/Nope/token': "Nope" isn't an item in the "Private" vault. Specify the item with its UUID, name, or domain.`, "EVE-104-201"},
+		{"missing field", `[ERROR] 2024/05/01 10:00:00 could not read secret 'op://Private/GitHub/nope': "nope" isn't a field in the "GitHub" item`, "EVE-104-201"},
+		{"app locked", `[ERROR] 2024/05/01 10:00:00 error initializing client: connecting to desktop app: 1Password is locked`, "EVE-104-502"},
+		{"not signed in", `[ERROR] 2024/05/01 10:00:00 You are not currently signed in. Please run ` + "`op signin --help`" + ` for instructions`, "EVE-104-503"},
+		{"session expired", `[ERROR] 2024/05/01 10:00:00 session expired, sign in to create a new session`, "EVE-104-503"},
+		{"other", `[ERROR] 2024/05/01 10:00:00 Post "https://my.1password.com/api": dial tcp: lookup my.1password.com: no such host`, "EVE-104-106"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			installCommandStub(t, "op", "#!/bin/sh\ncat >&2 <<'MSG'\n"+tc.stderr+"\nMSG\nexit 1\n")
+			_, err := (&OnePasswordCommand{}).Show(context.Background(), "Private/Nope/token")
+			exitErr := expectExitDetail(t, err, tc.want)
+			if !strings.Contains(exitErr.Error(), strings.TrimPrefix(tc.stderr, "[ERROR] 2024/05/01 10:00:00 ")) {
+				t.Fatalf("error does not carry the op message: %v", exitErr)
+			}
+		})
+	}
+}
+
+// [EVT-MZU-14]
+func TestOnePasswordCommandMissingBinary(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	_, err := (&OnePasswordCommand{}).Show(context.Background(), "Private/GitHub/token")
+	expectExitDetail(t, err, "EVE-104-1")
+}
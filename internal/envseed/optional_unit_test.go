@@ -0,0 +1,128 @@
+package envseed
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const optionalTemplate = "DB=<pass:db>\nSENTRY_DSN=<pass:sentry/dsn|optional>\nFLAG_KEY=<env:FLAG_KEY|default=off>\n"
+
+// optionalPass serves `db` and reports every other entry as not found.
+func optionalPass() *fakePass {
+	return &fakePass{
+		values: map[string]string{"db": "s3cret"},
+		errs:   map[string]error{"sentry/dsn": NewExitError("EVE-104-201", "pass", "sentry/dsn")},
+	}
+}
+
+// writeOptionalTemplate writes optionalTemplate and unsets FLAG_KEY for the
+// duration of the test.
+func writeOptionalTemplate(t *testing.T) string {
+	t.Helper()
+	t.Setenv("FLAG_KEY", "")
+	if err := os.Unsetenv("FLAG_KEY"); err != nil {
+		t.Fatalf("unset FLAG_KEY: %v", err)
+	}
+	input := filepath.Join(t.TempDir(), ".envseed")
+	if err := os.WriteFile(input, []byte(optionalTemplate), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	return input
+}
+
+// [EVT-MZU-19]
+func TestSyncOptionalPlaceholdersFallBack(t *testing.T) {
+	input := writeOptionalTemplate(t)
+	var stderr bytes.Buffer
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: optionalPass(), Stderr: &stderr}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(input), ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if want := "DB=s3cret\nSENTRY_DSN=\nFLAG_KEY=off\n"; string(data) != want {
+		t.Fatalf("output = %q, want %q", string(data), want)
+	}
+	for _, want := range []string{
+		"warning: " + input + ":2:12: SENTRY_DSN: <pass:sentry/dsn> not found; using empty value\n",
+		"warning: " + input + ":3:10: FLAG_KEY: <env:FLAG_KEY> not found; using default value\n",
+	} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Fatalf("stderr = %q, want it to contain %q", stderr.String(), want)
+		}
+	}
+
+	stderr.Reset()
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: optionalPass(), Quiet: true, Force: true, Stderr: &stderr}); err != nil {
+		t.Fatalf("Sync(quiet) error = %v", err)
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("quiet sync wrote to stderr: %q", stderr.String())
+	}
+}
+
+// [EVT-MZU-19][EVT-BCU-6]
+func TestDryRunAndDiffShowFallbacks(t *testing.T) {
+	input := writeOptionalTemplate(t)
+	var stdout bytes.Buffer
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: optionalPass(), DryRun: true, Stdout: &stdout, Stderr: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("Sync(dry-run) error = %v", err)
+	}
+	notes := "fallback: " + input + ":2:12: SENTRY_DSN: <pass:sentry/dsn> not found; using empty value\n" +
+		"fallback: " + input + ":3:10: FLAG_KEY: <env:FLAG_KEY> not found; using default value\n"
+	if !strings.HasSuffix(stdout.String(), notes) {
+		t.Fatalf("dry-run output = %q, want fallback notes %q", stdout.String(), notes)
+	}
+
+	for _, existing := range []string{"DB=old\n", "DB=s3cret\nSENTRY_DSN=\nFLAG_KEY=off\n"} {
+		if err := os.WriteFile(filepath.Join(filepath.Dir(input), ".env"), []byte(existing), 0o600); err != nil {
+			t.Fatalf("write existing: %v", err)
+		}
+		stdout.Reset()
+		var stderr bytes.Buffer
+		res, err := Diff(context.Background(), DiffOptions{InputPath: input, PassClient: optionalPass(), Stdout: &stdout, Stderr: &stderr})
+		if err != nil {
+			t.Fatalf("Diff() error = %v", err)
+		}
+		if res.Changed != (existing == "DB=old\n") {
+			t.Fatalf("Diff() changed = %v for %q", res.Changed, existing)
+		}
+		if res.Changed && !strings.HasSuffix(stdout.String(), notes) {
+			t.Fatalf("diff output = %q, want fallback notes %q", stdout.String(), notes)
+		}
+		if !res.Changed && stdout.Len() != 0 {
+			t.Fatalf("diff without changes wrote %q", stdout.String())
+		}
+		if want := strings.ReplaceAll(notes, "fallback: ", "warning: "); stderr.String() != want {
+			t.Fatalf("diff stderr = %q, want %q", stderr.String(), want)
+		}
+
+		stderr.Reset()
+		if _, err := Diff(context.Background(), DiffOptions{InputPath: input, PassClient: optionalPass(), Quiet: true, Stdout: io.Discard, Stderr: &stderr}); err != nil {
+			t.Fatalf("Diff(quiet) error = %v", err)
+		}
+		if stderr.Len() != 0 {
+			t.Fatalf("quiet diff wrote to stderr: %q", stderr.String())
+		}
+	}
+}
+
+// [EVT-MZU-19][EVT-MZU-2]
+func TestOptionalPlaceholderBackendFailureAborts(t *testing.T) {
+	pass := &fakePass{errs: map[string]error{"sentry/dsn": NewExitError("EVE-104-101", "sentry/dsn")}}
+	_, err := renderWithSchemes(t, "SENTRY_DSN=<pass:sentry/dsn|optional>\n", map[string]SchemeClient{"pass": pass})
+	expectExitDetail(t, err, "EVE-104-101")
+}
+
+// [EVT-MZU-19]
+func TestOptionalPlaceholderExistingEntryErrorAborts(t *testing.T) {
+	pass := &fakePass{values: map[string]string{"svc/login": "hunter2\n"}}
+	_, err := renderWithSchemes(t, "CODE=<otp:svc/login|optional>\n", map[string]SchemeClient{"otp": &OTPClient{Source: pass}})
+	expectExitDetail(t, err, "EVE-104-206")
+}
@@ -0,0 +1,151 @@
+package envseed
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTPClient implements SchemeClient for `<otp:PATH>` placeholders. It reads
+// the entry PATH from Source (the pass backend), finds the `otpauth://totp/`
+// URI stored in it (pass-otp convention) and returns the current TOTP code
+// (RFC 6238). Within a run, secretCache reads the entry through its (pass,
+// PATH) cache entry, shared with `<pass:PATH>` placeholders.
+type OTPClient struct {
+	// Source is the pass backend of the run.
+	Source SchemeClient
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+// Show returns the TOTP code for the entry at PATH.
+func (o *OTPClient) Show(ctx context.Context, path string) (string, error) {
+	entry, err := o.Source.Show(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	return o.codeFromEntry(path, entry)
+}
+
+// codeFromEntry returns the TOTP code of the URI stored in entry, the content
+// of the pass entry PATH.
+func (o *OTPClient) codeFromEntry(path, entry string) (string, error) {
+	uri, ok := findOTPAuthURI(entry)
+	if !ok {
+		return "", NewExitError("EVE-104-206", path)
+	}
+	params, err := parseOTPAuthURI(uri)
+	if err != nil {
+		return "", NewExitError("EVE-104-604", path).WithErr(err)
+	}
+	if params.newHash == nil {
+		return "", NewExitError("EVE-104-605", params.algorithm, path)
+	}
+	now := time.Now
+	if o.Now != nil {
+		now = o.Now
+	}
+	return params.code(now()), nil
+}
+
+// Prefetch forwards paths to Source when it batches retrievals.
+func (o *OTPClient) Prefetch(ctx context.Context, paths []string) {
+	if p, ok := o.Source.(Prefetcher); ok {
+		p.Prefetch(ctx, paths)
+	}
+}
+
+// findOTPAuthURI returns the first line of entry that starts with `otpauth://`.
+func findOTPAuthURI(entry string) (string, bool) {
+	for _, line := range strings.Split(entry, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "otpauth://") {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// totpParams are the parameters of an `otpauth://totp/` URI.
+type totpParams struct {
+	secret    []byte
+	algorithm string
+	newHash   func() hash.Hash
+	digits    int
+	period    int64
+}
+
+// parseOTPAuthURI validates a TOTP key URI. An unknown algorithm is not an
+// error here; it is reported with newHash == nil.
+func parseOTPAuthURI(uri string) (totpParams, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return totpParams{}, err
+	}
+	if u.Host != "totp" {
+		return totpParams{}, fmt.Errorf("unsupported OTP type %q (only totp)", u.Host)
+	}
+	q := u.Query()
+	secret := strings.ToUpper(strings.ReplaceAll(q.Get("secret"), " ", ""))
+	if secret == "" {
+		return totpParams{}, errors.New("missing secret parameter")
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return totpParams{}, fmt.Errorf("secret is not base32: %w", err)
+	}
+	p := totpParams{secret: key, algorithm: "SHA1", digits: 6, period: 30}
+	if alg := q.Get("algorithm"); alg != "" {
+		p.algorithm = strings.ToUpper(alg)
+	}
+	switch p.algorithm {
+	case "SHA1":
+		p.newHash = sha1.New
+	case "SHA256":
+		p.newHash = sha256.New
+	case "SHA512":
+		p.newHash = sha512.New
+	}
+	if d := q.Get("digits"); d != "" {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 6 || n > 8 {
+			return totpParams{}, fmt.Errorf("digits must be 6, 7, or 8, got %q", d)
+		}
+		p.digits = n
+	}
+	if s := q.Get("period"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 1 {
+			return totpParams{}, fmt.Errorf("period must be a positive number of seconds, got %q", s)
+		}
+		p.period = n
+	}
+	return p, nil
+}
+
+// code computes the TOTP value at t (RFC 6238 with T0 = 0).
+func (p totpParams) code(t time.Time) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/p.period))
+	mac := hmac.New(p.newHash, p.secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for range p.digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", p.digits, value%mod)
+}
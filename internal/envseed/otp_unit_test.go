@@ -0,0 +1,99 @@
+package envseed
+
+import (
+	"context"
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// otpURI builds a TOTP key URI for the raw secret key with extra parameters.
+func otpURI(key, params string) string {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte(key))
+	return "otpauth://totp/Example:alice@example.com?secret=" + secret + "&issuer=Example" + params
+}
+
+// [EVT-MZU-18]
+func TestOTPClientRFC6238Vectors(t *testing.T) {
+	// RFC 6238 Appendix B: the seed length matches the hash output size.
+	seeds := map[string]string{
+		"SHA1":   "12345678901234567890",
+		"SHA256": "12345678901234567890123456789012",
+		"SHA512": "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+	cases := []struct {
+		unix int64
+		alg  string
+		want string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1111111111, "SHA256", "67062674"},
+		{1234567890, "SHA512", "93441116"},
+		{2000000000, "SHA1", "69279037"},
+		{20000000000, "SHA256", "77737706"},
+	}
+	for _, tc := range cases {
+		pass := &fakePass{values: map[string]string{"totp": "hunter2\n" + otpURI(seeds[tc.alg], "&algorithm="+tc.alg+"&digits=8")}}
+		client := &OTPClient{Source: pass, Now: func() time.Time { return time.Unix(tc.unix, 0) }}
+		got, err := client.Show(context.Background(), "totp")
+		if err != nil {
+			t.Fatalf("%s at %d: Show error = %v", tc.alg, tc.unix, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s at %d: code = %q, want %q", tc.alg, tc.unix, got, tc.want)
+		}
+	}
+}
+
+// [EVT-MZU-18][EVT-MZU-4]
+func TestSyncResolvesOTPScheme(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	pass := &fakePass{values: map[string]string{
+		"web/login":  "hunter2\nuser: alice\n" + otpURI("12345678901234567890", ""),
+		"web/period": otpURI("12345678901234567890", "&period=60&digits=7"),
+	}}
+	clients := map[string]SchemeClient{"pass": pass, "otp": &OTPClient{Source: pass, Now: func() time.Time { return now }}}
+	got, err := renderWithSchemes(t, "PASS=<pass:web/login|first_line>\nCODE=<otp:web/login>\nSLOW=\"<otp:web/period>\"\n", clients)
+	if err != nil {
+		t.Fatalf("render error = %v", err)
+	}
+	// Counter 37037036 (30 s) and counter 18518518 (60 s, 7 digits).
+	if want := "PASS=hunter2\nCODE=081804\nSLOW=\"9360094\"\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+	// <pass:web/login> and <otp:web/login> share one read of the entry.
+	if pass.calls["web/login"] != 1 {
+		t.Fatalf("Show(web/login) called %d times, want 1", pass.calls["web/login"])
+	}
+}
+
+// [EVT-MZU-18][EVT-MZU-2]
+func TestOTPClientErrors(t *testing.T) {
+	key := "12345678901234567890"
+	pass := &fakePass{values: map[string]string{
+		"plain":     "hunter2",
+		"hotp":      "otpauth://hotp/Example?secret=GEZDGNBV&counter=1",
+		"nosecret":  "otpauth://totp/Example?issuer=Example",
+		"badsecret": "otpauth://totp/Example?secret=not-base32!",
+		"digits":    otpURI(key, "&digits=10"),
+		"period":    otpURI(key, "&period=0"),
+		"md5":       otpURI(key, "&algorithm=MD5"),
+	}}
+	client := &OTPClient{Source: pass, Now: func() time.Time { return time.Unix(59, 0) }}
+	for path, code := range map[string]string{
+		"plain":     "EVE-104-206",
+		"hotp":      "EVE-104-604",
+		"nosecret":  "EVE-104-604",
+		"badsecret": "EVE-104-604",
+		"digits":    "EVE-104-604",
+		"period":    "EVE-104-604",
+		"md5":       "EVE-104-605",
+		"missing":   "EVE-105-1",
+	} {
+		_, err := client.Show(context.Background(), path)
+		expectExitDetail(t, err, code)
+	}
+}
@@ -9,6 +9,32 @@ import (
 	"strings"
 )
 
+// Pass backends selectable with NewPassClient.
+const (
+	PassBackendPass   = "pass"
+	PassBackendGopass = "gopass"
+	PassBackendNative = "native"
+)
+
+// PassBackendEnv names the environment variable the CLI consults when no
+// backend flag is given.
+const PassBackendEnv = "ENVSEED_PASS_BACKEND"
+
+// NewPassClient returns the PassClient for the named backend. An empty name
+// selects pass.
+func NewPassClient(backend string) (PassClient, error) {
+	switch backend {
+	case "", PassBackendPass:
+		return &PassCommand{}, nil
+	case PassBackendGopass:
+		return &GopassCommand{}, nil
+	case PassBackendNative:
+		return &PassStore{}, nil
+	default:
+		return nil, NewExitError("EVE-101-7", backend)
+	}
+}
+
 // PassCommand implements PassClient using the pass CLI.
 type PassCommand struct{}
 
@@ -22,21 +48,50 @@ func (p *PassCommand) Show(ctx context.Context, path string) (string, error) {
 	out, err := cmd.Output()
 	if err != nil {
 		if errors.Is(err, exec.ErrNotFound) {
-			return "", NewExitError("EVE-104-1").WithErr(err)
+			return "", NewExitError("EVE-104-1", "pass").WithErr(err)
 		}
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
 			errMsg := stderr.String()
 			if passEntryNotFound(errMsg) {
-				return "", NewExitError("EVE-104-201", path).WithErr(err)
+				return "", NewExitError("EVE-104-201", "pass", path).WithErr(err)
 			}
-			return "", NewExitError("EVE-104-101", path).WithErr(err)
+			return "", NewExitError("EVE-104-101", "pass", path).WithErr(err)
 		}
-		return "", NewExitError("EVE-104-101", path).WithErr(err)
+		return "", NewExitError("EVE-104-101", "pass", path).WithErr(err)
 	}
 	return string(out), nil
 }
 
+// Insert creates PATH through `pass insert --multiline`, with value on stdin.
+func (p *PassCommand) Insert(ctx context.Context, path, value string) error {
+	return storeWithCommand(ctx, "EVE-104-702", "pass", []string{"insert", "--multiline", path}, path, value)
+}
+
+// Update replaces PATH through `pass insert --multiline --force`.
+func (p *PassCommand) Update(ctx context.Context, path, value string) error {
+	return storeWithCommand(ctx, "EVE-104-707", "pass", []string{"insert", "--multiline", "--force", path}, path, value)
+}
+
+// storeWithCommand runs a backend's insert command with value on stdin and
+// reports a failure with the detail code.
+func storeWithCommand(ctx context.Context, code, name string, args []string, path, value string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(value)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return NewExitError("EVE-104-1", name).WithErr(err)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.New(msg)
+		}
+		return NewExitError(code, name, path).WithErr(err)
+	}
+	return nil
+}
+
 // passEntryNotFound tries to recognize common 'not found' diagnostics from pass.
 func passEntryNotFound(msg string) bool {
 	m := strings.ToLower(msg)
@@ -2,42 +2,174 @@ package envseed
 
 import (
 	"context"
+	"sync"
+
+	"envseed/internal/ast"
+	"envseed/internal/renderer"
 )
 
-type passResolver struct {
-	ctx      context.Context
-	cache    *secretCache
-	closed   bool
-	rendered map[string][]string
+// secretResolver serves every placeholder scheme of a single run through one
+// secretCache, so each (scheme, PATH) pair is fetched at most once.
+type secretResolver struct {
+	ctx       context.Context
+	cache     *secretCache
+	closed    bool
+	rendered  map[string][]string
+	fallbacks []renderer.Fallback
 }
 
-func newPassResolver(ctx context.Context, client PassClient) *passResolver {
-	return &passResolver{
+func newSecretResolver(ctx context.Context, clients map[string]SchemeClient) *secretResolver {
+	return &secretResolver{
 		ctx:      ctx,
-		cache:    newSecretCache(client),
+		cache:    newSecretCache(clients),
 		rendered: make(map[string][]string),
 	}
 }
 
-func (r *passResolver) Resolve(path string) (string, error) {
+// newPassResolver returns a resolver that serves only `<pass:...>` placeholders.
+func newPassResolver(ctx context.Context, client PassClient) *secretResolver {
+	return newSecretResolver(ctx, map[string]SchemeClient{ast.SchemePass: client})
+}
+
+// Prefetch resolves every unique placeholder of elements before rendering, so
+// that rendering only reads the cache. Clients that implement Prefetcher first
+// receive every PATH of their scheme, grouped by scheme and in template order;
+// the placeholders are then resolved by up to jobs concurrent workers. Failures
+// are kept in the cache and reported when rendering reaches the placeholder,
+// so the first failing placeholder in template order is the one reported.
+func (r *secretResolver) Prefetch(elements []ast.Element, jobs int) {
+	if r.closed {
+		return
+	}
+	paths := make(map[string][]string)
+	var tokens []ast.ValueToken
+	seen := make(map[secretKey]bool)
+	seenPath := make(map[secretKey]bool)
+	for _, el := range elements {
+		if el.Type != ast.ElementAssignment || el.Assignment == nil {
+			continue
+		}
+		for _, tok := range el.Assignment.ValueTokens {
+			if tok.Kind != ast.ValuePlaceholder {
+				continue
+			}
+			key := r.cache.key(tok)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			tokens = append(tokens, tok)
+			if pathKey := (secretKey{scheme: key.scheme, path: key.path}); !seenPath[pathKey] {
+				seenPath[pathKey] = true
+				paths[key.scheme] = append(paths[key.scheme], key.path)
+			}
+		}
+	}
+	for scheme, list := range paths {
+		if p, ok := r.cache.clients[scheme].(Prefetcher); ok {
+			p.Prefetch(r.ctx, list)
+		}
+	}
+	r.resolveAll(tokens, jobs)
+}
+
+// jobCount returns the configured number of prefetch workers.
+func jobCount(jobs int) int {
+	if jobs == 0 {
+		return DefaultJobs
+	}
+	return jobs
+}
+
+// resolveAll resolves tokens through a pool of at most jobs workers. Tokens are
+// dispatched in order; dispatching stops once the context is cancelled.
+func (r *secretResolver) resolveAll(tokens []ast.ValueToken, jobs int) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > len(tokens) {
+		jobs = len(tokens)
+	}
+	work := make(chan ast.ValueToken)
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tok := range work {
+				_, _ = r.cache.getPlaceholder(r.ctx, tok)
+			}
+		}()
+	}
+	for _, tok := range tokens {
+		if r.ctx.Err() != nil {
+			break
+		}
+		work <- tok
+	}
+	close(work)
+	wg.Wait()
+}
+
+// tokenScheme returns the scheme of a placeholder token; tokens built without
+// one are `<pass:...>` placeholders.
+func tokenScheme(tok ast.ValueToken) string {
+	if tok.Scheme == "" {
+		return ast.SchemePass
+	}
+	return tok.Scheme
+}
+
+func (r *secretResolver) Resolve(path string) (string, error) {
+	return r.ResolveScheme(ast.SchemePass, path)
+}
+
+func (r *secretResolver) ResolveScheme(scheme, path string) (string, error) {
+	if r.closed {
+		return "", NewExitError("EVE-199-2")
+	}
+	entry, err := r.cache.get(r.ctx, scheme, path)
+	if err != nil {
+		return "", err
+	}
+	return entry.value, nil
+}
+
+// ResolvePlaceholder resolves tok through the cache, handing the full token to
+// clients that implement PlaceholderClient.
+func (r *secretResolver) ResolvePlaceholder(tok ast.ValueToken) (string, error) {
 	if r.closed {
 		return "", NewExitError("EVE-199-2")
 	}
-	entry, err := r.cache.get(r.ctx, path)
+	entry, err := r.cache.getPlaceholder(r.ctx, tok)
 	if err != nil {
 		return "", err
 	}
 	return entry.value, nil
 }
 
-func (r *passResolver) RecordRendered(path, value string) {
+func (r *secretResolver) RecordRendered(path, value string) {
 	if r.closed {
 		return
 	}
 	r.rendered[path] = append(r.rendered[path], value)
 }
 
-func (r *passResolver) RenderedValues() map[string][]string {
+// RecordFallback collects the placeholders rendered from their fallback, in
+// template order.
+func (r *secretResolver) RecordFallback(fb renderer.Fallback) {
+	if r.closed {
+		return
+	}
+	r.fallbacks = append(r.fallbacks, fb)
+}
+
+// Fallbacks returns the placeholders that fell back during rendering.
+func (r *secretResolver) Fallbacks() []renderer.Fallback {
+	return append([]renderer.Fallback(nil), r.fallbacks...)
+}
+
+func (r *secretResolver) RenderedValues() map[string][]string {
 	out := make(map[string][]string, len(r.rendered))
 	for path, values := range r.rendered {
 		copyValues := make([]string, len(values))
@@ -47,7 +179,7 @@ func (r *passResolver) RenderedValues() map[string][]string {
 	return out
 }
 
-func (r *passResolver) Close() {
+func (r *secretResolver) Close() {
 	if r.closed {
 		return
 	}
@@ -56,15 +188,17 @@ func (r *passResolver) Close() {
 	for k := range r.rendered {
 		delete(r.rendered, k)
 	}
+	r.fallbacks = nil
 }
 
-func (r *passResolver) Snapshot() map[string]string {
+func (r *secretResolver) Snapshot() map[string]string {
 	if r.cache == nil || r.cache.cache == nil {
 		return map[string]string{}
 	}
-	out := make(map[string]string, len(r.cache.cache))
-	for k, entry := range r.cache.cache {
-		out[k] = entry.value
+	values := r.cache.values()
+	out := make(map[string]string, len(values))
+	for k, value := range values {
+		out[k.scheme+":"+k.path] = value
 	}
 	return out
 }
@@ -124,7 +124,7 @@ func TestWrapRenderErrorReportsColumn(t *testing.T) {
 
 // [EVT-MPU-4]
 func TestWrapRenderErrorInvalidModifierCombination(t *testing.T) {
-	elems, err := parser.Parse("VAL=<pass:secret|base64,strip>\n")
+	elems, err := parser.Parse("VAL=<pass:secret|base64,allow_tab>\n")
 	if err != nil {
 		t.Fatalf("Parse error: %v", err)
 	}
@@ -142,7 +142,7 @@ func TestWrapRenderErrorInvalidModifierCombination(t *testing.T) {
 	if exitErr.DetailCode != "EVE-105-601" {
 		t.Fatalf("detail code = %s, want EVE-105-601", exitErr.DetailCode)
 	}
-	expectedDetail := "The `base64` modifier cannot be combined with any other modifier. Remove the other modifiers, including the strip family and `dangerously_bypass_escape`."
+	expectedDetail := "`dangerously_bypass_escape` cannot be combined with any other modifier, and `allow_tab` and `allow_newline` cannot be combined with a modifier pipeline that ends in an encoding (`base64`, `base64url`, `base64_nopad`, `hex`, `urlencode`), whose output never contains TAB or newlines. Remove the conflicting modifiers."
 	if exitErr.DetailText != expectedDetail {
 		t.Fatalf("detail text = %q, want %q", exitErr.DetailText, expectedDetail)
 	}
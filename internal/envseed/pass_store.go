@@ -0,0 +1,373 @@
+package envseed
+
+// Native password-store backend (`--pass-backend native`).
+// This file holds:
+//  - PassStore: reads `$PASSWORD_STORE_DIR` directly and decrypts entries with gpg
+//  - lookupStoreEntry: PATH -> .gpg file and the recipients of its nearest `.gpg-id`
+//  - parseDecryptStatus: per-file outcome of a batched gpg run
+//  - Insert: encrypts a new entry for `sync --generate-missing`
+// The batched gpg invocation itself is platform-specific (pass_store_unix.go).
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// PassStore implements PassClient by reading the password store directory
+// directly. Entries requested together through Prefetch are decrypted by a
+// single gpg process per `.gpg-id` recipient set instead of one `pass show`
+// (and one gpg) per PATH.
+type PassStore struct {
+	// Dir defaults to $PASSWORD_STORE_DIR, then ~/.password-store.
+	Dir string
+
+	mu        sync.Mutex
+	decrypted map[string]storeResult
+}
+
+type storeResult struct {
+	value string
+	err   error
+}
+
+// storeEntry is a PATH located in the store.
+type storeEntry struct {
+	path   string
+	file   string
+	keyIDs []string
+}
+
+// gpgOutcome is the per-file result of a batched decryption.
+type gpgOutcome struct {
+	plaintext string
+	ok        bool
+}
+
+// Show returns the decrypted content of PATH, using the result of an earlier
+// Prefetch when available. A prefetched value is handed out once and then
+// dropped from the store's memory.
+func (s *PassStore) Show(ctx context.Context, path string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.decrypted[path]; !ok {
+		s.prefetch(ctx, []string{path})
+	}
+	res := s.decrypted[path]
+	delete(s.decrypted, path)
+	return res.value, res.err
+}
+
+// Prefetch decrypts every PATH in paths, batching entries that share the same
+// `.gpg-id` recipients into one gpg invocation.
+func (s *PassStore) Prefetch(ctx context.Context, paths []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prefetch(ctx, paths)
+}
+
+// prefetch implements Prefetch; the caller holds s.mu.
+func (s *PassStore) prefetch(ctx context.Context, paths []string) {
+	if s.decrypted == nil {
+		s.decrypted = make(map[string]storeResult)
+	}
+	dir := s.storeDir()
+	var order []string
+	groups := make(map[string][]storeEntry)
+	for _, path := range paths {
+		if _, done := s.decrypted[path]; done {
+			continue
+		}
+		entry, err := lookupStoreEntry(dir, path)
+		if err != nil {
+			s.decrypted[path] = storeResult{err: err}
+			continue
+		}
+		key := strings.Join(entry.keyIDs, "\n")
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], entry)
+	}
+	for _, key := range order {
+		s.decryptGroup(ctx, groups[key])
+	}
+}
+
+func (s *PassStore) decryptGroup(ctx context.Context, entries []storeEntry) {
+	files := make([]string, len(entries))
+	for i, entry := range entries {
+		files[i] = entry.file
+	}
+	outcomes, stderr, err := decryptBatch(ctx, gpgDecryptArgs(entries[0].keyIDs), files)
+	if err != nil {
+		for _, entry := range entries {
+			exitErr := NewExitError("EVE-104-101", "pass", entry.path)
+			if errors.Is(err, exec.ErrNotFound) {
+				exitErr = NewExitError("EVE-104-1", "gpg")
+			}
+			s.decrypted[entry.path] = storeResult{err: exitErr.WithErr(err)}
+		}
+		return
+	}
+	for i, entry := range entries {
+		if outcomes[i].ok {
+			s.decrypted[entry.path] = storeResult{value: outcomes[i].plaintext}
+			continue
+		}
+		cause := errors.New("gpg could not decrypt " + entry.file)
+		if msg := strings.TrimSpace(stderr); msg != "" {
+			cause = errors.New(msg)
+		}
+		s.decrypted[entry.path] = storeResult{err: NewExitError("EVE-104-101", "pass", entry.path).WithErr(cause)}
+	}
+}
+
+// Insert encrypts value to the key IDs of the nearest `.gpg-id` and writes it
+// as the .gpg file of PATH, like `pass insert`. An existing file is never
+// replaced.
+func (s *PassStore) Insert(ctx context.Context, path, value string) error {
+	return s.encryptTo(ctx, "EVE-104-702", path, value, writeNewFile)
+}
+
+// Update encrypts value like Insert and replaces the .gpg file of PATH
+// atomically, creating it when missing.
+func (s *PassStore) Update(ctx context.Context, path, value string) error {
+	return s.encryptTo(ctx, "EVE-104-707", path, value, replaceFile)
+}
+
+// encryptTo encrypts value for PATH and stores the ciphertext with write,
+// reporting failures with the detail code.
+func (s *PassStore) encryptTo(ctx context.Context, code, path, value string, write func(string, []byte) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.decrypted, path)
+	dir := s.storeDir()
+	file, ok := storeFile(dir, path)
+	if !ok {
+		return NewExitError(code, "pass", path).WithErr(errEscapesStore)
+	}
+	keyIDs, err := nearestGPGIDs(dir, filepath.Dir(file))
+	if err != nil {
+		return NewExitError(code, "pass", path).WithErr(err)
+	}
+	if len(keyIDs) == 0 {
+		return NewExitError(code, "pass", path).WithErr(errors.New("no .gpg-id names a key to encrypt to"))
+	}
+	cmd := exec.CommandContext(ctx, "gpg", gpgEncryptArgs(keyIDs)...)
+	cmd.Stdin = strings.NewReader(value)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	ciphertext, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return NewExitError("EVE-104-1", "gpg").WithErr(err)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.New(msg)
+		}
+		return NewExitError(code, "pass", path).WithErr(err)
+	}
+	if err := write(file, ciphertext); err != nil {
+		return NewExitError(code, "pass", path).WithErr(err)
+	}
+	return nil
+}
+
+// writeNewFile creates file (and its folders) with mode 0600 and writes data.
+// It fails if file already exists.
+func writeNewFile(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(file)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(file)
+		return err
+	}
+	return nil
+}
+
+// replaceFile writes data to a temporary file next to file with mode 0600
+// and renames it over file.
+func replaceFile(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".envseed-*.gpg")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+func (s *PassStore) storeDir() string {
+	if s.Dir != "" {
+		return s.Dir
+	}
+	if dir := os.Getenv("PASSWORD_STORE_DIR"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".password-store")
+}
+
+// lookupStoreEntry maps PATH to its .gpg file below dir and collects the key
+// IDs of the nearest `.gpg-id`, searching from the entry's folder up to dir.
+func lookupStoreEntry(dir, path string) (storeEntry, error) {
+	file, ok := storeFile(dir, path)
+	if !ok {
+		return storeEntry{}, NewExitError("EVE-104-101", "pass", path).WithErr(errEscapesStore)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return storeEntry{}, NewExitError("EVE-104-201", "pass", path).WithErr(err)
+		}
+		return storeEntry{}, NewExitError("EVE-104-101", "pass", path).WithErr(err)
+	}
+	if !info.Mode().IsRegular() {
+		return storeEntry{}, NewExitError("EVE-104-101", "pass", path).WithErr(errors.New(file + " is not a regular file"))
+	}
+	keyIDs, err := nearestGPGIDs(dir, filepath.Dir(file))
+	if err != nil {
+		return storeEntry{}, NewExitError("EVE-104-101", "pass", path).WithErr(err)
+	}
+	return storeEntry{path: path, file: file, keyIDs: keyIDs}, nil
+}
+
+// errEscapesStore reports a PATH that names a file outside the store.
+var errEscapesStore = errors.New("path escapes the password store")
+
+// storeFile returns the .gpg file of PATH below dir, or false when PATH would
+// leave dir.
+func storeFile(dir, path string) (string, bool) {
+	rel := filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.Join(dir, rel+".gpg"), true
+}
+
+// nearestGPGIDs reads the first `.gpg-id` found walking up from folder to root.
+// A store without any `.gpg-id` yields no key IDs; gpg then picks the secret
+// key from the message itself.
+func nearestGPGIDs(root, folder string) ([]string, error) {
+	for {
+		data, err := os.ReadFile(filepath.Join(folder, ".gpg-id"))
+		if err == nil {
+			return parseGPGIDs(string(data)), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if folder == root || filepath.Dir(folder) == folder {
+			return nil, nil
+		}
+		folder = filepath.Dir(folder)
+	}
+}
+
+// parseGPGIDs returns one key ID per line, ignoring blank lines and `#` comments.
+func parseGPGIDs(content string) []string {
+	var ids []string
+	sc := bufio.NewScanner(strings.NewReader(content))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			ids = append(ids, line)
+		}
+	}
+	return ids
+}
+
+// gpgDecryptArgs returns the gpg options shared by every batch. Recipients from
+// `.gpg-id` are offered as secret keys to try, which also covers stores whose
+// messages hide recipient key IDs.
+func gpgDecryptArgs(keyIDs []string) []string {
+	args := strings.Fields(os.Getenv("PASSWORD_STORE_GPG_OPTS"))
+	args = append(args, "--batch", "--yes", "--quiet")
+	for _, id := range keyIDs {
+		args = append(args, "--try-secret-key", id)
+	}
+	return args
+}
+
+// gpgEncryptArgs returns the gpg options that encrypt stdin to keyIDs, as
+// `pass insert` does.
+func gpgEncryptArgs(keyIDs []string) []string {
+	args := strings.Fields(os.Getenv("PASSWORD_STORE_GPG_OPTS"))
+	args = append(args, "--batch", "--yes", "--quiet", "--compress-algo=none", "--no-encrypt-to", "--encrypt")
+	for _, id := range keyIDs {
+		args = append(args, "-r", id)
+	}
+	return args
+}
+
+// parseDecryptStatus reports, for each of names, whether gpg's status output
+// contains a successful decryption between its FILE_START and FILE_DONE lines.
+func parseDecryptStatus(status string, names []string) []bool {
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	ok := make([]bool, len(names))
+	current, failed := -1, false
+	for _, line := range strings.Split(status, "\n") {
+		fields := strings.Fields(strings.TrimPrefix(line, "[GNUPG:] "))
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "FILE_START":
+			current, failed = -1, false
+			if len(fields) >= 3 {
+				if i, found := index[strings.Join(fields[2:], " ")]; found {
+					current = i
+				}
+			}
+		case "DECRYPTION_OKAY":
+			if current >= 0 && !failed {
+				ok[current] = true
+			}
+		case "DECRYPTION_FAILED", "BADMDC":
+			failed = true
+			if current >= 0 {
+				ok[current] = false
+			}
+		case "FILE_DONE":
+			current = -1
+		}
+	}
+	return ok
+}
@@ -0,0 +1,34 @@
+//go:build !unix && !darwin
+
+package envseed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// decryptBatch provides a conservative fallback on non-Unix builds: one gpg
+// process per file, since FIFOs are unavailable.
+func decryptBatch(ctx context.Context, gpgArgs []string, files []string) ([]gpgOutcome, string, error) {
+	outcomes := make([]gpgOutcome, len(files))
+	var stderr bytes.Buffer
+	for i, file := range files {
+		args := append(append([]string{}, gpgArgs...), "--decrypt", file)
+		cmd := exec.CommandContext(ctx, "gpg", args...)
+		cmd.Stdin = os.Stdin
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				return nil, "", err
+			}
+			continue
+		}
+		outcomes[i] = gpgOutcome{plaintext: string(out), ok: true}
+	}
+	return outcomes, stderr.String(), nil
+}
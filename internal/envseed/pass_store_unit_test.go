@@ -0,0 +1,286 @@
+package envseed
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubGPG stands in for `gpg --decrypt-files` and `gpg --encrypt`:
+// "ciphertext" is plaintext, a file containing only FAIL fails to decrypt, and
+// every run is logged.
+const stubGPG = `#!/bin/sh
+echo "$*" >> "$STUB_GPG_LOG"
+case " $* " in
+  *" --encrypt "*) cat; exit 0 ;;
+esac
+decrypt=0
+for arg; do
+  case "$arg" in
+    --decrypt-files) decrypt=1 ;;
+    *.gpg)
+      [ $decrypt = 1 ] || continue
+      echo "[GNUPG:] FILE_START 3 $arg" >&3
+      if [ "$(cat "$arg")" = FAIL ]; then
+        echo "gpg: decryption failed: No secret key" >&2
+        echo "[GNUPG:] DECRYPTION_FAILED" >&3
+      else
+        cat "$arg" > "${arg%.gpg}"
+        echo "[GNUPG:] DECRYPTION_OKAY" >&3
+      fi
+      echo "[GNUPG:] FILE_DONE" >&3
+      ;;
+  esac
+done
+`
+
+// newStubStore builds a password store from files (PATH -> content, plus
+// `.gpg-id` files) and installs the stub gpg. It returns the store dir and the
+// gpg invocation log.
+func newStubStore(t *testing.T, files map[string]string) (string, string) {
+	t.Helper()
+	base := t.TempDir()
+	store := filepath.Join(base, "store")
+	for name, content := range files {
+		full := filepath.Join(store, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o700); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	bin := filepath.Join(base, "bin")
+	if err := os.Mkdir(bin, 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(bin, "gpg"), []byte(stubGPG), 0o755); err != nil {
+		t.Fatalf("write stub: %v", err)
+	}
+	log := filepath.Join(base, "gpg.log")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("STUB_GPG_LOG", log)
+	t.Setenv("PASSWORD_STORE_GPG_OPTS", "")
+	return store, log
+}
+
+func gpgRuns(t *testing.T, log string) []string {
+	t.Helper()
+	data, err := os.ReadFile(log)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		t.Fatalf("read log: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+// [EVT-MZU-10]
+func TestPassStoreBatchesByGPGID(t *testing.T) {
+	store, log := newStubStore(t, map[string]string{
+		".gpg-id":           "root@example.com\n",
+		"a.gpg":             "alpha\n",
+		"b.gpg":             "bravo\n",
+		"team/.gpg-id":      "# team key\nteam@example.com\n",
+		"team/c.gpg":        "charlie\n",
+		"team/nested/d.gpg": "delta\n",
+	})
+	s := &PassStore{Dir: store}
+	s.Prefetch(context.Background(), []string{"a", "team/c", "b", "team/nested/d"})
+
+	runs := gpgRuns(t, log)
+	if len(runs) != 2 {
+		t.Fatalf("gpg ran %d times, want 2 (one per .gpg-id):\n%s", len(runs), strings.Join(runs, "\n"))
+	}
+	if !strings.Contains(runs[0], "--try-secret-key root@example.com") || strings.Contains(runs[0], "team@") {
+		t.Fatalf("first batch args = %q", runs[0])
+	}
+	if !strings.Contains(runs[1], "--try-secret-key team@example.com") {
+		t.Fatalf("second batch args = %q", runs[1])
+	}
+
+	for path, want := range map[string]string{"a": "alpha\n", "b": "bravo\n", "team/c": "charlie\n", "team/nested/d": "delta\n"} {
+		got, err := s.Show(context.Background(), path)
+		if err != nil {
+			t.Fatalf("Show(%q) error: %v", path, err)
+		}
+		if got != want {
+			t.Fatalf("Show(%q) = %q, want %q", path, got, want)
+		}
+	}
+	if runs := gpgRuns(t, log); len(runs) != 2 {
+		t.Fatalf("Show re-ran gpg after Prefetch: %d runs", len(runs))
+	}
+}
+
+// [EVT-MZU-10][EVT-MZU-2]
+func TestPassStoreErrors(t *testing.T) {
+	store, log := newStubStore(t, map[string]string{
+		"ok.gpg":    "fine\n",
+		"bad.gpg":   "FAIL",
+		"dir/x.gpg": "x\n",
+	})
+	s := &PassStore{Dir: store}
+	s.Prefetch(context.Background(), []string{"ok", "bad", "missing", "dir", "../escape"})
+	if runs := gpgRuns(t, log); len(runs) != 1 {
+		t.Fatalf("gpg ran %d times, want 1", len(runs))
+	}
+	if got, err := s.Show(context.Background(), "ok"); err != nil || got != "fine\n" {
+		t.Fatalf("Show(ok) = %q, %v", got, err)
+	}
+	for path, code := range map[string]string{
+		"bad":       "EVE-104-101",
+		"missing":   "EVE-104-201",
+		"dir":       "EVE-104-201",
+		"../escape": "EVE-104-101",
+	} {
+		_, err := s.Show(context.Background(), path)
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) || exitErr.DetailCode != code {
+			t.Fatalf("Show(%q) error = %v, want %s", path, err, code)
+		}
+	}
+}
+
+// [EVT-MZU-10]
+func TestPassStoreMissingGPG(t *testing.T) {
+	store, _ := newStubStore(t, map[string]string{"a.gpg": "alpha\n"})
+	t.Setenv("PATH", t.TempDir())
+	_, err := (&PassStore{Dir: store}).Show(context.Background(), "a")
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.DetailCode != "EVE-104-1" {
+		t.Fatalf("expected EVE-104-1, got %v", err)
+	}
+}
+
+// [EVT-MZU-10][EVT-MZU-4]
+func TestSyncNativeStoreSingleGPGRun(t *testing.T) {
+	store, log := newStubStore(t, map[string]string{
+		"db/user.gpg":     "alice\n",
+		"db/password.gpg": "s3cret\n",
+		"api/token.gpg":   "tok\n",
+	})
+	t.Setenv("PASSWORD_STORE_DIR", store)
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	template := "USER=<pass:db/user>\nPASS=<pass:db/password>\nTOKEN=<pass:api/token>\nAGAIN=<pass:db/user>\n"
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	client, err := NewPassClient(PassBackendNative)
+	if err != nil {
+		t.Fatalf("NewPassClient: %v", err)
+	}
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: client, Quiet: true}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if want := "USER=alice\nPASS=s3cret\nTOKEN=tok\nAGAIN=alice\n"; string(data) != want {
+		t.Fatalf("output = %q, want %q", string(data), want)
+	}
+	if runs := gpgRuns(t, log); len(runs) != 1 {
+		t.Fatalf("gpg ran %d times, want 1", len(runs))
+	}
+}
+
+// [EVT-MZU-10][EVT-MUU-1]
+func TestSyncNativeStoreRejectsNUL(t *testing.T) {
+	store, _ := newStubStore(t, map[string]string{"bad.gpg": "a\x00b\n"})
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	if err := os.WriteFile(input, []byte("V=<pass:bad>\n"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: &PassStore{Dir: store}, Quiet: true})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.DetailCode != "EVE-104-301" {
+		t.Fatalf("expected EVE-104-301, got %v", err)
+	}
+}
+
+// [EVT-MZU-10]
+func TestParseDecryptStatus(t *testing.T) {
+	status := strings.Join([]string{
+		"[GNUPG:] FILE_START 3 /tmp/x/0.gpg",
+		"[GNUPG:] DECRYPTION_OKAY",
+		"[GNUPG:] FILE_DONE",
+		"[GNUPG:] FILE_START 3 /tmp/x/1.gpg",
+		"[GNUPG:] DECRYPTION_FAILED",
+		"[GNUPG:] FILE_DONE",
+		"[GNUPG:] FILE_START 3 /tmp/x/2.gpg",
+		"[GNUPG:] DECRYPTION_OKAY",
+		"[GNUPG:] BADMDC",
+		"[GNUPG:] FILE_DONE",
+	}, "\n")
+	got := parseDecryptStatus(status, []string{"/tmp/x/0.gpg", "/tmp/x/1.gpg", "/tmp/x/2.gpg", "/tmp/x/3.gpg"})
+	want := []bool{true, false, false, false}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("parseDecryptStatus = %v, want %v", got, want)
+		}
+	}
+}
+
+// [EVT-MZU-20]
+func TestPassStoreInsert(t *testing.T) {
+	store, log := newStubStore(t, map[string]string{
+		".gpg-id":      "root@example.com\n",
+		"team/.gpg-id": "team@example.com\n",
+		"team/old.gpg": "old\n",
+	})
+	s := &PassStore{Dir: store}
+	if err := s.Insert(context.Background(), "team/jwt/key", "tok3n\n"); err != nil {
+		t.Fatalf("Insert error: %v", err)
+	}
+	file := filepath.Join(store, "team", "jwt", "key.gpg")
+	info, err := os.Stat(file)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("stat %s = %v, %v; want mode 0600", file, info, err)
+	}
+	if got, err := s.Show(context.Background(), "team/jwt/key"); err != nil || got != "tok3n\n" {
+		t.Fatalf("Show after Insert = %q, %v", got, err)
+	}
+	if runs := gpgRuns(t, log); len(runs) == 0 || !strings.HasSuffix(runs[0], "--encrypt -r team@example.com") {
+		t.Fatalf("gpg runs = %q, want an encryption to team@example.com first", runs)
+	}
+
+	expectExitDetail(t, s.Insert(context.Background(), "team/old", "x\n"), "EVE-104-702")
+	expectExitDetail(t, s.Insert(context.Background(), "../outside", "x\n"), "EVE-104-702")
+	bare, _ := newStubStore(t, nil)
+	expectExitDetail(t, (&PassStore{Dir: bare}).Insert(context.Background(), "key", "x\n"), "EVE-104-702")
+}
+
+// [EVT-MZU-22]
+func TestPassStoreUpdate(t *testing.T) {
+	store, _ := newStubStore(t, map[string]string{
+		".gpg-id":      "root@example.com\n",
+		"team/old.gpg": "old\n",
+	})
+	s := &PassStore{Dir: store}
+	if got, err := s.Show(context.Background(), "team/old"); err != nil || got != "old\n" {
+		t.Fatalf("Show before Update = %q, %v", got, err)
+	}
+	for path, value := range map[string]string{"team/old": "n3w\n", "team/new": "fresh\n"} {
+		if err := s.Update(context.Background(), path, value); err != nil {
+			t.Fatalf("Update(%s) error: %v", path, err)
+		}
+		if got, err := s.Show(context.Background(), path); err != nil || got != value {
+			t.Fatalf("Show(%s) after Update = %q, %v; want %q", path, got, err, value)
+		}
+	}
+	file := filepath.Join(store, "team", "old.gpg")
+	if info, err := os.Stat(file); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("stat %s = %v, %v; want mode 0600", file, info, err)
+	}
+	if entries, err := os.ReadDir(filepath.Join(store, "team")); err != nil || len(entries) != 2 {
+		t.Fatalf("team folder = %v, %v; want old.gpg and new.gpg only", entries, err)
+	}
+	expectExitDetail(t, s.Update(context.Background(), "../outside", "x\n"), "EVE-104-707")
+}
@@ -0,0 +1,112 @@
+//go:build unix || darwin
+
+package envseed
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// decryptBatch decrypts files with a single `gpg --decrypt-files` process.
+// gpg writes each plaintext next to its input with the `.gpg` suffix removed;
+// every input is therefore symlinked into a private directory whose output
+// names are FIFOs read in-process, so plaintext never reaches the disk.
+// Per-file success is taken from gpg's status output.
+func decryptBatch(ctx context.Context, gpgArgs []string, files []string) ([]gpgOutcome, string, error) {
+	tmp, err := os.MkdirTemp("", "envseed-gpg-")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	names := make([]string, len(files))
+	readers := make([]*os.File, 0, len(files))
+	holders := make([]*os.File, 0, len(files))
+	release := func() {
+		for _, f := range holders {
+			_ = f.Close()
+		}
+		holders = nil
+	}
+	defer func() {
+		release()
+		for _, f := range readers {
+			_ = f.Close()
+		}
+	}()
+	for i, file := range files {
+		out := filepath.Join(tmp, strconv.Itoa(i))
+		names[i] = out + ".gpg"
+		if err := os.Symlink(file, names[i]); err != nil {
+			return nil, "", err
+		}
+		if err := syscall.Mkfifo(out, 0o600); err != nil {
+			return nil, "", err
+		}
+		// Open the read end without waiting for a writer, then hold a write
+		// end ourselves so readers see EOF only once gpg is done with every file.
+		r, err := os.OpenFile(out, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+		if err != nil {
+			return nil, "", err
+		}
+		readers = append(readers, r)
+		w, err := os.OpenFile(out, os.O_WRONLY, 0)
+		if err != nil {
+			return nil, "", err
+		}
+		holders = append(holders, w)
+	}
+
+	plaintexts := make([][]byte, len(files))
+	var wg sync.WaitGroup
+	for i, r := range readers {
+		wg.Add(1)
+		go func(i int, r *os.File) {
+			defer wg.Done()
+			plaintexts[i], _ = io.ReadAll(r)
+		}(i, r)
+	}
+
+	statusR, statusW, err := os.Pipe()
+	if err != nil {
+		release()
+		wg.Wait()
+		return nil, "", err
+	}
+	defer statusR.Close()
+	args := append(append([]string{}, gpgArgs...), "--status-fd", "3", "--decrypt-files")
+	cmd := exec.CommandContext(ctx, "gpg", append(args, names...)...)
+	// Connect stdin so that interactive pinentry can receive user input.
+	cmd.Stdin = os.Stdin
+	cmd.ExtraFiles = []*os.File{statusW}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		_ = statusW.Close()
+		release()
+		wg.Wait()
+		return nil, "", err
+	}
+	_ = statusW.Close()
+	status, _ := io.ReadAll(statusR)
+	// A non-zero exit only means some file failed; the status lines say which.
+	_ = cmd.Wait()
+	release()
+	wg.Wait()
+
+	ok := parseDecryptStatus(string(status), names)
+	outcomes := make([]gpgOutcome, len(files))
+	for i := range files {
+		if ok[i] {
+			outcomes[i] = gpgOutcome{plaintext: string(plaintexts[i]), ok: true}
+		}
+	}
+	return outcomes, stderr.String(), nil
+}
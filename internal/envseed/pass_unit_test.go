@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -62,3 +63,49 @@ func TestPassCommandShowFailureExit(t *testing.T) {
 		t.Fatalf("detail code = %s, want EVE-104-101", exitErr.DetailCode)
 	}
 }
+
+// [EVT-MZU-20]
+func TestPassCommandInsert(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "inserted")
+	installCommandStub(t, "pass", `#!/bin/sh
+if [ "$1" != "insert" ] || [ "$2" != "--multiline" ] || [ "$3" != "jwt/key" ]; then
+  echo "Error: jwt/key already exists" >&2
+  exit 1
+fi
+cat > "`+out+`"
+`)
+	if err := (&PassCommand{}).Insert(context.Background(), "jwt/key", "tok3n\n"); err != nil {
+		t.Fatalf("Insert error: %v", err)
+	}
+	if data, err := os.ReadFile(out); err != nil || string(data) != "tok3n\n" {
+		t.Fatalf("stdin = %q, %v; want %q", data, err, "tok3n\n")
+	}
+	err := (&PassCommand{}).Insert(context.Background(), "other", "x\n")
+	exitErr := expectExitDetail(t, err, "EVE-104-702")
+	if !strings.Contains(exitErr.Error(), "already exists") {
+		t.Fatalf("EVE-104-702 does not carry the pass message: %v", exitErr)
+	}
+}
+
+// [EVT-MZU-22]
+func TestPassCommandUpdate(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "updated")
+	installCommandStub(t, "pass", `#!/bin/sh
+if [ "$1 $2 $3 $4" != "insert --multiline --force jwt/key" ]; then
+  echo "Error: unexpected args: $*" >&2
+  exit 1
+fi
+cat > "`+out+`"
+`)
+	if err := (&PassCommand{}).Update(context.Background(), "jwt/key", "n3w\n"); err != nil {
+		t.Fatalf("Update error: %v", err)
+	}
+	if data, err := os.ReadFile(out); err != nil || string(data) != "n3w\n" {
+		t.Fatalf("stdin = %q, %v; want %q", data, err, "n3w\n")
+	}
+	err := (&PassCommand{}).Update(context.Background(), "other", "x\n")
+	exitErr := expectExitDetail(t, err, "EVE-104-707")
+	if !strings.Contains(exitErr.Error(), "unexpected args") {
+		t.Fatalf("EVE-104-707 does not carry the pass message: %v", exitErr)
+	}
+}
@@ -11,7 +11,7 @@ import (
 func TestResolveOutputPathDerivation(t *testing.T) {
 	dir := t.TempDir()
 	input := filepath.Join(dir, "config.envseed.local")
-	got, err := resolveOutputPath(input, "")
+	got, err := resolveOutputPath(input, "", "")
 	if err != nil {
 		t.Fatalf("resolveOutputPath error: %v", err)
 	}
@@ -20,7 +20,7 @@ func TestResolveOutputPathDerivation(t *testing.T) {
 		t.Fatalf("output path = %q, want %q", got, want)
 	}
 	inputMulti := filepath.Join(dir, "multi.envseed.envseed")
-	got, err = resolveOutputPath(inputMulti, "")
+	got, err = resolveOutputPath(inputMulti, "", "")
 	if err != nil {
 		t.Fatalf("resolveOutputPath error: %v", err)
 	}
@@ -34,7 +34,7 @@ func TestResolveOutputPathDerivation(t *testing.T) {
 func TestResolveOutputPathRequiresEnvseed(t *testing.T) {
 	dir := t.TempDir()
 	input := filepath.Join(dir, "config.env")
-	_, err := resolveOutputPath(input, "")
+	_, err := resolveOutputPath(input, "", "")
 	if err == nil {
 		t.Fatal("expected error for input lacking 'envseed'")
 	}
@@ -53,7 +53,7 @@ func TestResolveOutputPathDirectoryTrailingSeparator(t *testing.T) {
 		t.Fatalf("mkdir: %v", err)
 	}
 	explicit := subdir + string(os.PathSeparator)
-	got, err := resolveOutputPath(input, explicit)
+	got, err := resolveOutputPath(input, explicit, "")
 	if err != nil {
 		t.Fatalf("resolveOutputPath error: %v", err)
 	}
@@ -79,3 +79,28 @@ func TestValidateOutputPathRejectsDirectory(t *testing.T) {
 		}
 	}
 }
+
+// [EVT-BIU-3][EVT-MGU-9]
+func TestResolveOutputPathProfileSuffix(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	got, err := resolveOutputPath(input, "", "prod")
+	if err != nil {
+		t.Fatalf("resolveOutputPath error: %v", err)
+	}
+	if want := filepath.Join(dir, ".env.prod"); got != want {
+		t.Fatalf("output path = %q, want %q", got, want)
+	}
+	got, err = resolveOutputPath(input, dir+string(os.PathSeparator), "prod")
+	if err != nil {
+		t.Fatalf("resolveOutputPath error: %v", err)
+	}
+	if want := filepath.Join(dir, ".env.prod"); got != want {
+		t.Fatalf("output path in directory = %q, want %q", got, want)
+	}
+	explicit := filepath.Join(dir, "custom.env")
+	got, err = resolveOutputPath(input, explicit, "prod")
+	if err != nil || got != explicit {
+		t.Fatalf("explicit output path = %q, %v; want %q", got, err, explicit)
+	}
+}
@@ -13,13 +13,16 @@ import (
 	"strings"
 )
 
-func resolveOutputPath(input, explicit string) (string, error) {
+// resolveOutputPath derives the target path from input unless explicit names a
+// file. With a profile, derived names end in `.<profile>`, so `.envseed`
+// targets `.env.prod` for the prod profile.
+func resolveOutputPath(input, explicit, profile string) (string, error) {
 	var candidate string
 	if explicit == "" {
 		if !strings.Contains(input, "envseed") {
 			return "", NewExitError("EVE-101-201", input)
 		}
-		candidate = strings.Replace(input, "envseed", "env", 1)
+		candidate = strings.Replace(input, "envseed", "env", 1) + profileSuffix(profile)
 	} else {
 		if strings.HasSuffix(explicit, string(os.PathSeparator)) {
 			dir := strings.TrimSuffix(explicit, string(os.PathSeparator))
@@ -36,12 +39,12 @@ func resolveOutputPath(input, explicit string) (string, error) {
 			if !info.IsDir() {
 				return "", NewExitError("EVE-106-3", dir)
 			}
-			candidate = filepath.Join(dir, deriveOutputFilename(input))
+			candidate = filepath.Join(dir, deriveOutputFilename(input, profile))
 		} else {
 			info, err := os.Stat(explicit)
 			switch {
 			case err == nil && info.IsDir():
-				candidate = filepath.Join(explicit, deriveOutputFilename(input))
+				candidate = filepath.Join(explicit, deriveOutputFilename(input, profile))
 			case err == nil:
 				candidate = explicit
 			case os.IsNotExist(err):
@@ -58,12 +61,19 @@ func resolveOutputPath(input, explicit string) (string, error) {
 	return abs, nil
 }
 
-func deriveOutputFilename(input string) string {
+func deriveOutputFilename(input, profile string) string {
 	name := filepath.Base(input)
 	if strings.Contains(name, "envseed") {
-		return strings.Replace(name, "envseed", "env", 1)
+		name = strings.Replace(name, "envseed", "env", 1)
 	}
-	return name
+	return name + profileSuffix(profile)
+}
+
+func profileSuffix(profile string) string {
+	if profile == "" {
+		return ""
+	}
+	return "." + profile
 }
 
 func validateOutputPath(path string) error {
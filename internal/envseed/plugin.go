@@ -0,0 +1,165 @@
+package envseed
+
+// External resolver plugins (`<x-NAME:PATH>`).
+// This file holds:
+//  - PlaceholderClient: optional interface for clients that see the full token
+//  - PluginClient: runs `envseed-resolver-NAME` with one JSON request per PATH
+//  - pluginRequest / pluginResponse: the versioned wire format
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"envseed/internal/ast"
+)
+
+// PluginProtocolVersion is the resolver plugin protocol version sent in every
+// request and required in every response.
+const PluginProtocolVersion = 1
+
+// PluginCommandPrefix prefixes the executable name of a plugin scheme: the
+// scheme `x-NAME` is served by `envseed-resolver-NAME` found on PATH.
+const PluginCommandPrefix = "envseed-resolver-"
+
+// PlaceholderClient is implemented by scheme clients that want the modifiers
+// and quoting context of the placeholder being resolved. Values are cached per
+// (scheme, PATH, modifiers, context), so each distinct occurrence is resolved.
+type PlaceholderClient interface {
+	SchemeClient
+	ShowPlaceholder(ctx context.Context, tok ast.ValueToken) (string, error)
+}
+
+// PluginClient implements PlaceholderClient for a plugin scheme by running its
+// executable once per distinct placeholder: the request is written to stdin
+// and a single response is read from stdout.
+type PluginClient struct {
+	// Scheme is the placeholder scheme, `x-NAME`.
+	Scheme string
+}
+
+type pluginRequest struct {
+	Version   int      `json:"version"`
+	Scheme    string   `json:"scheme"`
+	Path      string   `json:"path"`
+	Modifiers []string `json:"modifiers"`
+	Context   string   `json:"context"`
+}
+
+type pluginResponse struct {
+	Version int          `json:"version"`
+	Value   *string      `json:"value"`
+	Error   *pluginError `json:"error"`
+}
+
+type pluginError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Show resolves PATH without placeholder details (bare context, no modifiers).
+func (p *PluginClient) Show(ctx context.Context, path string) (string, error) {
+	return p.ShowPlaceholder(ctx, ast.ValueToken{Kind: ast.ValuePlaceholder, Scheme: p.Scheme, Path: path})
+}
+
+// ShowPlaceholder sends tok to the plugin and returns the value it answers.
+func (p *PluginClient) ShowPlaceholder(ctx context.Context, tok ast.ValueToken) (string, error) {
+	name, _ := ast.PluginName(p.Scheme)
+	command := PluginCommandPrefix + name
+	modifiers := make([]string, 0, len(tok.Modifiers))
+	for _, m := range tok.Modifiers {
+		modifiers = append(modifiers, m.String())
+	}
+	req, err := json.Marshal(pluginRequest{
+		Version:   PluginProtocolVersion,
+		Scheme:    p.Scheme,
+		Path:      tok.Path,
+		Modifiers: modifiers,
+		Context:   contextName(tok.Context),
+	})
+	if err != nil {
+		return "", NewExitError("EVE-104-108", p.Scheme, tok.Path).WithErr(err)
+	}
+	cmd := exec.CommandContext(ctx, command)
+	cmd.Stdin = bytes.NewReader(req)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+	if errors.Is(runErr, exec.ErrNotFound) {
+		return "", NewExitError("EVE-104-1", command).WithErr(runErr)
+	}
+	var resp pluginResponse
+	dec := json.NewDecoder(&stdout)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&resp); err != nil {
+		if runErr != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				runErr = errors.New(msg)
+			}
+			return "", NewExitError("EVE-104-107", p.Scheme, tok.Path).WithErr(runErr)
+		}
+		return "", NewExitError("EVE-104-108", p.Scheme, tok.Path).WithErr(err)
+	}
+	if resp.Version != PluginProtocolVersion {
+		return "", NewExitError("EVE-104-108", p.Scheme, tok.Path).WithErr(fmt.Errorf("unsupported protocol version %d", resp.Version))
+	}
+	if resp.Error != nil {
+		return "", p.responseError(tok.Path, resp.Error)
+	}
+	if resp.Value == nil {
+		return "", NewExitError("EVE-104-108", p.Scheme, tok.Path).WithErr(errors.New("response has neither value nor error"))
+	}
+	if runErr != nil {
+		return "", NewExitError("EVE-104-108", p.Scheme, tok.Path).WithErr(fmt.Errorf("value returned with failing exit status: %w", runErr))
+	}
+	return *resp.Value, nil
+}
+
+// responseError maps a structured plugin error onto the resolver subcodes
+// shared with the built-in backends; the plugin's message is attached.
+func (p *PluginClient) responseError(path string, perr *pluginError) error {
+	msg := perr.Message
+	if msg == "" {
+		msg = perr.Code
+	}
+	cause := errors.New(msg)
+	switch perr.Code {
+	case "not_found":
+		return NewExitError("EVE-104-201", p.Scheme, path).WithErr(cause)
+	case "permission_denied":
+		return NewExitError("EVE-104-501", p.Scheme, path).WithErr(cause)
+	case "locked":
+		return NewExitError("EVE-104-502", p.Scheme).WithErr(cause)
+	case "unauthenticated":
+		return NewExitError("EVE-104-503", p.Scheme).WithErr(cause)
+	default:
+		return NewExitError("EVE-104-107", p.Scheme, path).WithErr(cause)
+	}
+}
+
+// contextName is the protocol name of a placeholder's quoting context.
+func contextName(c ast.ValueContext) string {
+	switch c {
+	case ast.ContextDoubleQuoted:
+		return "double_quoted"
+	case ast.ContextSingleQuoted:
+		return "single_quoted"
+	case ast.ContextCommandSubstitution:
+		return "command_substitution"
+	case ast.ContextBacktick:
+		return "backtick"
+	case ast.ContextDollarSingleQuoted:
+		return "dollar_single_quoted"
+	case ast.ContextParameterExpansion:
+		return "parameter_expansion"
+	case ast.ContextDoubleQuotedParameterExpansion:
+		return "double_quoted_parameter_expansion"
+	default:
+		return "bare"
+	}
+}
@@ -0,0 +1,111 @@
+package envseed
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// stubPlugin answers protocol version 1 requests according to their PATH and
+// logs every request.
+const stubPlugin = `#!/bin/sh
+req=$(cat)
+echo "$req" >> "$STUB_PLUGIN_LOG"
+case "$req" in
+  *'"path":"db/password"'*) printf '{"version":1,"value":"p@ss word\\n"}' ;;
+  *'"path":"missing"'*) printf '{"version":1,"error":{"code":"not_found","message":"no such item: missing"}}' ;;
+  *'"path":"denied"'*) printf '{"version":1,"error":{"code":"permission_denied","message":"policy forbids denied"}}' ;;
+  *'"path":"locked"'*) printf '{"version":1,"error":{"code":"locked","message":"keychain is locked"}}' ;;
+  *'"path":"expired"'*) printf '{"version":1,"error":{"code":"unauthenticated","message":"token expired"}}' ;;
+  *'"path":"broken"'*) printf '{"version":1,"error":{"code":"backend_down","message":"upstream unavailable"}}' ;;
+  *'"path":"crash"'*) echo "panic: boom" >&2; exit 2 ;;
+  *'"path":"garbage"'*) printf 'not json' ;;
+  *'"path":"v2"'*) printf '{"version":2,"value":"x"}' ;;
+  *'"path":"empty"'*) printf '{"version":1}' ;;
+  *'"path":"nul"'*) printf '{"version":1,"value":"a\\u0000b"}' ;;
+  *) printf '{"version":1,"value":"other"}' ;;
+esac
+`
+
+func installStubPlugin(t *testing.T, name string) string {
+	t.Helper()
+	installCommandStub(t, PluginCommandPrefix+name, stubPlugin)
+	log := filepath.Join(t.TempDir(), "plugin.log")
+	t.Setenv("STUB_PLUGIN_LOG", log)
+	return log
+}
+
+// [EVT-MZU-15][EVT-MZU-4]
+func TestSyncResolvesPluginScheme(t *testing.T) {
+	log := installStubPlugin(t, "keychain")
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	template := "A=\"<x-keychain:db/password|strip_right>\"\nB=<x-keychain:db/password|strip>\nC='<x-keychain:api>'\nD=<x-keychain:api>\nE=\"<x-keychain:db/password|strip_right>\"\n"
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: &fakePass{}, Quiet: true}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if want := "A=\"p@ss word\"\nB=p@ss\\ word\nC='other'\nD=other\nE=\"p@ss word\"\n"; string(data) != want {
+		t.Fatalf("output = %q, want %q", string(data), want)
+	}
+	logData, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	requests := strings.Split(strings.TrimSpace(string(logData)), "\n")
+	sort.Strings(requests)
+	want := []string{
+		`{"version":1,"scheme":"x-keychain","path":"api","modifiers":[],"context":"bare"}`,
+		`{"version":1,"scheme":"x-keychain","path":"api","modifiers":[],"context":"single_quoted"}`,
+		`{"version":1,"scheme":"x-keychain","path":"db/password","modifiers":["strip"],"context":"bare"}`,
+		`{"version":1,"scheme":"x-keychain","path":"db/password","modifiers":["strip_right"],"context":"double_quoted"}`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("plugin requests = %q, want %q", requests, want)
+	}
+}
+
+// [EVT-MZU-15][EVT-MZU-2]
+func TestPluginErrors(t *testing.T) {
+	installStubPlugin(t, "keychain")
+	cases := []struct {
+		path, code, message string
+	}{
+		{"missing", "EVE-104-201", "no such item: missing"},
+		{"denied", "EVE-104-501", "policy forbids denied"},
+		{"locked", "EVE-104-502", "keychain is locked"},
+		{"expired", "EVE-104-503", "token expired"},
+		{"broken", "EVE-104-107", "upstream unavailable"},
+		{"crash", "EVE-104-107", "panic: boom"},
+		{"garbage", "EVE-104-108", ""},
+		{"v2", "EVE-104-108", "unsupported protocol version 2"},
+		{"empty", "EVE-104-108", "neither value nor error"},
+		{"nul", "EVE-104-302", ""},
+	}
+	for _, tc := range cases {
+		_, err := renderWithSchemes(t, "V=<x-keychain:"+tc.path+">\n", map[string]SchemeClient{})
+		exitErr := expectExitDetail(t, err, tc.code)
+		if tc.message != "" && !strings.Contains(exitErr.Error(), tc.message) {
+			t.Fatalf("%s: error does not carry %q: %v", tc.path, tc.message, exitErr)
+		}
+	}
+}
+
+// [EVT-MZU-15]
+func TestPluginMissingExecutable(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	_, err := renderWithSchemes(t, "V=<x-nowhere:key>\n", map[string]SchemeClient{})
+	exitErr := expectExitDetail(t, err, "EVE-104-1")
+	if !strings.Contains(exitErr.Error(), "envseed-resolver-nowhere") {
+		t.Fatalf("error does not name the plugin executable: %v", exitErr)
+	}
+}
@@ -0,0 +1,138 @@
+package envseed
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"envseed/internal/parser"
+)
+
+// gatedClient blocks every Show until release is closed (when set) and records
+// call counts and the peak number of concurrent calls.
+type gatedClient struct {
+	release chan struct{}
+	delay   map[string]time.Duration
+	fail    map[string]bool
+
+	mu      sync.Mutex
+	calls   map[string]int
+	active  int
+	maxSeen int
+}
+
+func (g *gatedClient) Show(ctx context.Context, path string) (string, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]int)
+	}
+	g.calls[path]++
+	g.active++
+	if g.active > g.maxSeen {
+		g.maxSeen = g.active
+	}
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.active--
+		g.mu.Unlock()
+	}()
+	if g.release != nil {
+		<-g.release
+	}
+	time.Sleep(g.delay[path])
+	if g.fail[path] {
+		return "", NewExitError("EVE-104-201", "pass", path)
+	}
+	return "value-" + path + "\n", nil
+}
+
+// [EVT-MZU-16]
+func TestPrefetchBoundsConcurrencyAndRendersFromCache(t *testing.T) {
+	client := &gatedClient{delay: map[string]time.Duration{}}
+	var lines []string
+	for i := range 12 {
+		path := "k" + strconv.Itoa(i)
+		client.delay[path] = 20 * time.Millisecond
+		lines = append(lines, "V"+strconv.Itoa(i)+"=<pass:"+path+">", "W"+strconv.Itoa(i)+"=<pass:"+path+">")
+	}
+	elems, err := parser.Parse(strings.Join(lines, "\n") + "\n")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	resolver := newPassResolver(context.Background(), client)
+	defer resolver.Close()
+	resolver.Prefetch(elems, 3)
+
+	client.mu.Lock()
+	if client.maxSeen != 3 {
+		t.Fatalf("peak concurrency = %d, want 3", client.maxSeen)
+	}
+	if len(client.calls) != 12 {
+		t.Fatalf("prefetch resolved %d paths, want 12", len(client.calls))
+	}
+	for path, n := range client.calls {
+		if n != 1 {
+			t.Fatalf("%s fetched %d times, want 1", path, n)
+		}
+	}
+	client.mu.Unlock()
+
+	client.release = make(chan struct{}) // any further backend call would block
+	for i := range 12 {
+		path := "k" + strconv.Itoa(i)
+		got, err := resolver.Resolve(path)
+		if err != nil || got != "value-"+path+"\n" {
+			t.Fatalf("Resolve(%s) = %q, %v", path, got, err)
+		}
+	}
+}
+
+// [EVT-MZU-16]
+func TestSecretCacheCollapsesConcurrentRequests(t *testing.T) {
+	client := &gatedClient{release: make(chan struct{})}
+	cache := newSecretCache(map[string]SchemeClient{"pass": client})
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if entry, err := cache.get(context.Background(), "pass", "shared"); err != nil || entry.value != "value-shared\n" {
+				t.Errorf("get = %q, %v", entry.value, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(client.release)
+	wg.Wait()
+	if n := client.calls["shared"]; n != 1 {
+		t.Fatalf("backend called %d times, want 1", n)
+	}
+}
+
+// [EVT-MZU-16][EVT-MZU-4]
+func TestSyncReportsFirstFailureInTemplateOrder(t *testing.T) {
+	// "late" fails last but comes first in the template.
+	client := &gatedClient{
+		delay: map[string]time.Duration{"late": 80 * time.Millisecond},
+		fail:  map[string]bool{"late": true, "early": true},
+	}
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	template := "A=<pass:ok>\nB=<pass:late>\nC=<pass:ok2>\nD=<pass:early>\n"
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	for range 3 {
+		err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: client, Jobs: 4, Quiet: true})
+		exitErr := expectExitDetail(t, err, "EVE-104-201")
+		if !strings.Contains(exitErr.Error(), `"late"`) {
+			t.Fatalf("reported error = %v, want the failure of %q", exitErr, "late")
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package envseed
+
+import (
+	"slices"
+
+	"envseed/internal/ast"
+	"envseed/internal/parser"
+)
+
+// checkProfileName rejects --profile values that cannot name a profile.
+func checkProfileName(profile string) error {
+	if profile != "" && !parser.IsProfileName(profile) {
+		return NewExitError("EVE-101-14", profile)
+	}
+	return nil
+}
+
+// applyProfile expands the template variables of the parsed template for
+// profile. When the template declares profiles, profile must be one of them.
+func applyProfile(elements []ast.Element, profile string) ([]ast.Element, error) {
+	if profile != "" {
+		profiles, err := parser.Profiles(elements)
+		if err != nil {
+			return nil, wrapParseError(err)
+		}
+		if len(profiles) > 0 && !slices.Contains(profiles, profile) {
+			return nil, NewExitError("EVE-101-15", profile)
+		}
+	}
+	expanded, err := parser.ExpandProfile(elements, profile)
+	if err != nil {
+		return nil, wrapParseError(err)
+	}
+	return expanded, nil
+}
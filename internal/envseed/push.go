@@ -0,0 +1,327 @@
+package envseed
+
+// Push (`envseed push`): writes values edited in the target .env back into the
+// pass store. This file holds:
+//  - Push: renders the template, compares it with the .env and updates entries
+//  - planPush: maps each edited assignment to the entry its placeholder names
+//  - confirmPush: the masked summary and the confirmation prompt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"envseed/internal/ast"
+	"envseed/internal/parser"
+	"envseed/internal/renderer"
+)
+
+// pushChange is one entry to update from the edited .env.
+type pushChange struct {
+	name string
+	line int
+	path string
+	// old is the current value without its EOF newline; missing reports an
+	// entry that does not exist yet.
+	old     string
+	missing bool
+	// value is the entry to store, with the EOF newline of the old entry.
+	value string
+}
+
+// Push executes the envseed push workflow. It renders the template like diff,
+// pairs each assignment with the same assignment (by name and occurrence) of
+// the resolved .env, and updates the `<pass:...>` entry of every value that
+// differs. All edited values are checked before any entry is updated.
+func Push(ctx context.Context, opts PushOptions) error {
+	passClient := opts.PassClient
+	if passClient == nil {
+		passClient = &PassCommand{}
+	}
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	if err := checkProfileName(opts.Profile); err != nil {
+		return err
+	}
+	data, err := readPushInput(opts.InputPath)
+	if err != nil {
+		return err
+	}
+	targetPath, err := resolveOutputPath(opts.InputPath, opts.OutputPath, opts.Profile)
+	if err != nil {
+		return err
+	}
+	elements, err := parser.ParseFile(opts.InputPath, string(data))
+	if err != nil {
+		return wrapParseError(err)
+	}
+	elements, err = applyProfile(elements, opts.Profile)
+	if err != nil {
+		return err
+	}
+	elements = rebaseIncludedPaths(elements, filepath.Dir(opts.InputPath))
+	targetData, err := readPushInput(targetPath)
+	if err != nil {
+		return err
+	}
+	targetElements, err := ParseTarget(string(targetData))
+	if err != nil {
+		return err
+	}
+
+	resolver := newSecretResolver(ctx, schemeClients(passClient, schemeConfig{
+		baseDir:     filepath.Dir(opts.InputPath),
+		ageIdentity: opts.AgeIdentity,
+	}, opts.Schemes))
+	defer resolver.Close()
+	resolver.Prefetch(elements, jobCount(opts.Jobs))
+	rendered, err := renderer.RenderElements(elements, resolver)
+	if err != nil {
+		return wrapRenderError(err)
+	}
+	renderedElements, err := ParseTarget(rendered)
+	if err != nil {
+		return err
+	}
+
+	changes, err := planPush(elements, renderedElements, targetElements, resolver)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		if !opts.Quiet {
+			fmt.Fprintf(stderr, "push: %s matches the store\n", targetPath)
+		}
+		return nil
+	}
+	if !opts.Quiet || opts.DryRun {
+		writePushSummary(stderr, changes)
+	}
+	if opts.DryRun {
+		return nil
+	}
+	updater, ok := passClient.(Updater)
+	if !ok {
+		return NewExitError("EVE-104-706", "pass")
+	}
+	if !opts.Yes {
+		confirmed, err := confirmPush(opts.Stdin, stderr, len(changes))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintln(stderr, "push: cancelled; no entries updated")
+			return nil
+		}
+	}
+	for _, change := range changes {
+		if err := updater.Update(ctx, change.path, change.value); err != nil {
+			return err
+		}
+		if !opts.Quiet {
+			fmt.Fprintf(stderr, "updated: <pass:%s>\n", change.path)
+		}
+	}
+	return nil
+}
+
+// readPushInput reads a file push depends on, classifying failures like the
+// selected input (exit code 102).
+func readPushInput(path string) ([]byte, error) {
+	if info, statErr := os.Lstat(path); statErr != nil {
+		code := classifyStatDetail(statErr)
+		return nil, NewExitError(code, path).WithErr(statErr)
+	} else if info.IsDir() {
+		return nil, NewExitError("EVE-102-2", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return nil, NewExitError("EVE-102-101", path).WithErr(err)
+		}
+		return nil, NewExitError("EVE-102-202", path).WithErr(err)
+	}
+	return data, nil
+}
+
+// planPush compares the rendered and the edited .env assignment by assignment
+// and returns the entries to update, in .env order. An edited value must come
+// from a template value that is exactly one `<pass:...>` placeholder whose
+// modifiers do not change the value, and must render with it again.
+func planPush(elements, renderedElements, targetElements []ast.Element, resolver *secretResolver) ([]pushChange, error) {
+	templateAssignments := assignmentsOf(elements)
+	renderedAssignments := assignmentsOf(renderedElements)
+	rendered := make(map[string]int)
+	for i, key := range occurrenceKeys(renderedAssignments) {
+		rendered[key] = i
+	}
+
+	var changes []pushChange
+	index := make(map[string]int)
+	targetAssignments := assignmentsOf(targetElements)
+	for i, key := range occurrenceKeys(targetAssignments) {
+		target := targetAssignments[i]
+		j, ok := rendered[key]
+		if !ok || j >= len(templateAssignments) || !valueEdited(renderedAssignments[j], target) {
+			continue
+		}
+		tok, err := pushPlaceholder(templateAssignments[j], target)
+		if err != nil {
+			return nil, err
+		}
+		segs, _, reason := decodeEnvValue(valueText(target.ValueTokens))
+		if reason != "" {
+			return nil, NewExitError("EVE-107-503", target.Name, target.Line).WithErr(errors.New(reason))
+		}
+		change := pushChange{name: target.Name, line: target.Line, path: tok.Path}
+		suffix := "\n"
+		old, err := resolver.ResolveScheme(ast.SchemePass, tok.Path)
+		var exitErr *ExitError
+		switch {
+		case err == nil:
+			change.old = trimEOFNewline(old)
+			suffix = old[len(change.old):]
+		case errors.As(err, &exitErr) && exitErr.MissingValue():
+			change.missing = true
+		default:
+			return nil, err
+		}
+		change.value = joinSegments(segs) + suffix
+		if err := renderer.CheckValue(*tok, change.value); err != nil {
+			return nil, NewExitError("EVE-107-503", target.Name, target.Line).WithErr(err)
+		}
+		if k, ok := index[change.path]; ok {
+			if changes[k].value != change.value {
+				return nil, NewExitError("EVE-107-504", target.Name, target.Line)
+			}
+			continue
+		}
+		index[change.path] = len(changes)
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// assignmentsOf returns the assignments of elements in order.
+func assignmentsOf(elements []ast.Element) []*ast.Assignment {
+	var out []*ast.Assignment
+	for _, el := range elements {
+		if el.Type == ast.ElementAssignment && el.Assignment != nil {
+			out = append(out, el.Assignment)
+		}
+	}
+	return out
+}
+
+// occurrenceKeys identifies each assignment by its name and occurrence, e.g.
+// `A#2` for the second assignment of A.
+func occurrenceKeys(assignments []*ast.Assignment) []string {
+	seen := make(map[string]int)
+	keys := make([]string, len(assignments))
+	for i, as := range assignments {
+		seen[as.Name]++
+		keys[i] = fmt.Sprintf("%s#%d", as.Name, seen[as.Name])
+	}
+	return keys
+}
+
+// valueEdited reports whether the edited assignment assigns another value than
+// the rendered one. Values are compared as the shell assigns them, so changing
+// only the quoting is not an edit.
+func valueEdited(rendered, edited *ast.Assignment) bool {
+	renderedText, editedText := valueText(rendered.ValueTokens), valueText(edited.ValueTokens)
+	renderedSegs, _, renderedReason := decodeEnvValue(renderedText)
+	editedSegs, _, editedReason := decodeEnvValue(editedText)
+	if renderedReason != "" || editedReason != "" {
+		return strings.TrimRight(renderedText, " \t") != strings.TrimRight(editedText, " \t")
+	}
+	return joinSegments(renderedSegs) != joinSegments(editedSegs)
+}
+
+// pushPlaceholder returns the placeholder of a template value that is exactly
+// one `<pass:...>` placeholder, optionally quoted, without modifiers that
+// transform or select the value. Errors name the edited assignment.
+func pushPlaceholder(as, edited *ast.Assignment) (*ast.ValueToken, error) {
+	var tok *ast.ValueToken
+	var quotes strings.Builder
+	for i := range as.ValueTokens {
+		if as.ValueTokens[i].Kind != ast.ValuePlaceholder {
+			quotes.WriteString(as.ValueTokens[i].Text)
+			continue
+		}
+		if tok != nil {
+			return nil, NewExitError("EVE-107-501", edited.Name, edited.Line)
+		}
+		tok = &as.ValueTokens[i]
+	}
+	if q := quotes.String(); tok == nil || (q != "" && q != `""` && q != "''" && q != "$''") {
+		return nil, NewExitError("EVE-107-501", edited.Name, edited.Line)
+	}
+	if tokenScheme(*tok) != ast.SchemePass {
+		return nil, NewExitError("EVE-107-502", edited.Name, edited.Line).WithErr(fmt.Errorf("<%s:...> entries cannot be updated", tok.Scheme))
+	}
+	for _, m := range tok.Modifiers {
+		switch m.Name {
+		case "allow_newline", "allow_tab", "optional", "default", "gen_len", "gen_charset":
+		default:
+			return nil, NewExitError("EVE-107-502", edited.Name, edited.Line).WithErr(fmt.Errorf("modifier %q changes the rendered value", m.Name))
+		}
+	}
+	return tok, nil
+}
+
+// trimEOFNewline removes one trailing LF or CRLF, like rendering does.
+func trimEOFNewline(s string) string {
+	if strings.HasSuffix(s, "\r\n") {
+		return s[:len(s)-2]
+	}
+	return strings.TrimSuffix(s, "\n")
+}
+
+// writePushSummary lists the entries to update with masked old and new
+// values (Section 6.3).
+func writePushSummary(w io.Writer, changes []pushChange) {
+	for _, c := range changes {
+		old := maskWithRevealPreservingNewlines(c.old)
+		if c.missing {
+			old = "(missing)"
+		}
+		value := maskWithRevealPreservingNewlines(trimEOFNewline(c.value))
+		fmt.Fprintf(w, "push: line %d: %s: <pass:%s>: %s -> %s\n", c.line, c.name, c.path, old, value)
+	}
+}
+
+// confirmPush asks whether to update n entries and reads the answer from in.
+// A nil in selects os.Stdin, which must be a terminal.
+func confirmPush(in io.Reader, stderr io.Writer, n int) (bool, error) {
+	if in == nil {
+		info, err := os.Stdin.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false, NewExitError("EVE-101-12")
+		}
+		in = os.Stdin
+	}
+	noun := "entries"
+	if n == 1 {
+		noun = "entry"
+	}
+	fmt.Fprintf(stderr, "update %d pass %s? [y/N] ", n, noun)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(stderr)
+		return false, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
@@ -34,7 +34,7 @@ func ParseTarget(text string) ([]ast.Element, error) {
 			if perr.DetailCode == "EVE-103-103" {
 				return nil, NewExitError("EVE-107-1").WithErr(err)
 			}
-			// Unterminated constructs mapping (103-401..404 → 107-201..204)
+			// Unterminated constructs mapping (103-401..404 → 107-201..204, 103-405..406 → 107-206..207)
 			switch perr.DetailCode {
 			case "EVE-103-401":
 				return nil, NewExitError("EVE-107-201").WithErr(err)
@@ -44,6 +44,10 @@ func ParseTarget(text string) ([]ast.Element, error) {
 				return nil, NewExitError("EVE-107-203").WithErr(err)
 			case "EVE-103-404":
 				return nil, NewExitError("EVE-107-204").WithErr(err)
+			case "EVE-103-405":
+				return nil, NewExitError("EVE-107-206").WithErr(err)
+			case "EVE-103-406":
+				return nil, NewExitError("EVE-107-207").WithErr(err)
 			}
 			// Generic parse failure
 			return nil, NewExitError("EVE-107-205").WithErr(err)
@@ -125,8 +129,9 @@ func MaskEnv(text string) (string, error) {
 			}
 		case ast.ElementAssignment:
 			as := el.Assignment
-			// Leading whitespace + name + operator
+			// Leading whitespace + declaration + name + operator
 			b.WriteString(as.LeadingWhitespace)
+			b.WriteString(as.Declaration)
 			b.WriteString(as.Name)
 			if as.Operator == ast.OperatorAppend {
 				b.WriteString("+=")
@@ -160,7 +165,9 @@ func valueText(tokens []ast.ValueToken) string {
 }
 
 // maskValueString masks string segments while preserving syntactic delimiters
-// (quotes, $(), backticks) and backslashes, with newline preservation.
+// (quotes, $'...', $(), ${...}, backticks) and backslashes, with newline
+// preservation. The parameter name and operator of `${NAME:-word}` are kept,
+// so only the word is masked.
 func maskValueString(s string) string {
 	type frameKind int
 	const (
@@ -169,6 +176,9 @@ func maskValueString(s string) string {
 		frameSingle
 		frameBacktick
 		frameCommand
+		frameDollarSingle
+		frameParam
+		frameDoubleParam
 	)
 	type frame struct {
 		kind  frameKind
@@ -214,8 +224,35 @@ func maskValueString(s string) string {
 		}
 
 		if !escaped {
+			// ANSI-C quote start: $'
+			if r == '$' && (top == frameBare || top == frameCommand || top == frameParam) {
+				if nr, nsize := utf8.DecodeRuneInString(s[i+size:]); nr == '\'' {
+					flush()
+					write(r)
+					write(nr)
+					stack = append(stack, frame{kind: frameDollarSingle})
+					i += size + nsize
+					continue
+				}
+			}
+			// Parameter expansion start: ${NAME followed by its operator
+			if r == '$' && top != frameSingle && top != frameDollarSingle && top != frameBacktick {
+				if nr, nsize := utf8.DecodeRuneInString(s[i+size:]); nr == '{' {
+					flush()
+					head := i + size + nsize
+					head += paramHeadLen(s[head:])
+					out.WriteString(s[i:head])
+					kind := frameParam
+					if top == frameDouble || top == frameDoubleParam {
+						kind = frameDoubleParam
+					}
+					stack = append(stack, frame{kind: kind})
+					i = head
+					continue
+				}
+			}
 			// Command substitution start: $(
-			if r == '$' && top != frameSingle && top != frameBacktick {
+			if r == '$' && top != frameSingle && top != frameDollarSingle && top != frameBacktick {
 				// Look ahead for '('
 				if nr, nsize := utf8.DecodeRuneInString(s[i+size:]); nr == '(' {
 					flush()
@@ -232,7 +269,7 @@ func maskValueString(s string) string {
 				write(r)
 				if top == frameDouble {
 					stack = stack[:len(stack)-1]
-				} else if top != frameSingle && top != frameBacktick {
+				} else if top != frameSingle && top != frameDollarSingle && top != frameBacktick {
 					stack = append(stack, frame{kind: frameDouble})
 				}
 				i += size
@@ -240,9 +277,9 @@ func maskValueString(s string) string {
 			case '\'':
 				flush()
 				write(r)
-				if top == frameSingle {
+				if top == frameSingle || top == frameDollarSingle {
 					stack = stack[:len(stack)-1]
-				} else if top != frameDouble && top != frameBacktick {
+				} else if top != frameDouble && top != frameDoubleParam && top != frameBacktick {
 					stack = append(stack, frame{kind: frameSingle})
 				}
 				i += size
@@ -252,11 +289,19 @@ func maskValueString(s string) string {
 				write(r)
 				if top == frameBacktick {
 					stack = stack[:len(stack)-1]
-				} else if top != frameSingle {
+				} else if top != frameSingle && top != frameDollarSingle {
 					stack = append(stack, frame{kind: frameBacktick})
 				}
 				i += size
 				continue
+			case '}':
+				if top == frameParam || top == frameDoubleParam {
+					flush()
+					write(r)
+					stack = stack[:len(stack)-1]
+					i += size
+					continue
+				}
 			case ')':
 				if top == frameCommand {
 					flush()
@@ -297,6 +342,10 @@ func maskValueString(s string) string {
 			// escape pair: elide backslash and mask following code point
 			seg.WriteByte('*')
 			i += size + nsize
+			if top == frameDollarSingle {
+				// \xHH and \NNN span more than one code point after the backslash
+				i += dollarSingleEscapeTail(s[i:], nr)
+			}
 			// ensure escaped state does not leak
 			if escaped {
 				escaped = false
@@ -316,6 +365,44 @@ func maskValueString(s string) string {
 	return out.String()
 }
 
+// paramHeadLen returns the length of the parameter name and operator at the
+// start of a `${...}` body, e.g. `DB_HOST:-` in `${DB_HOST:-word}`.
+func paramHeadLen(body string) int {
+	n := 0
+	for n < len(body) && (body[n] == '_' || body[n] == '#' || body[n] == '!' || isASCIIAlnum(body[n])) {
+		n++
+	}
+	if n < len(body) && body[n] == ':' {
+		n++
+	}
+	if n < len(body) && strings.IndexByte("-=+?", body[n]) >= 0 {
+		n++
+	}
+	return n
+}
+
+func isASCIIAlnum(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// dollarSingleEscapeTail returns the length of the digits that follow the
+// escape letter c of a `$'...'` escape: up to two hex digits after `x`, or up
+// to two more octal digits after a leading octal digit.
+func dollarSingleEscapeTail(rest string, c rune) int {
+	n := 0
+	switch {
+	case c == 'x':
+		for n < len(rest) && n < 2 && strings.IndexByte("0123456789abcdefABCDEF", rest[n]) >= 0 {
+			n++
+		}
+	case c >= '0' && c <= '7':
+		for n < len(rest) && n < 2 && rest[n] >= '0' && rest[n] <= '7' {
+			n++
+		}
+	}
+	return n
+}
+
 // maskWithRevealPreservingNewlines applies head/tail reveal per line,
 // preserving CR/LF characters.
 func maskWithRevealPreservingNewlines(s string) string {
@@ -20,6 +20,7 @@ func TestMaskEnv_ElidesBackslashesInSegments(t *testing.T) {
 		{"bare_space", "KEY=abc\\ xyz\n", true},
 		{"top_level_hash", "KEY=abc\\#xyz\n", true},
 		{"single_quote_literal", "KEY='a\\$b'\n", false}, // backslash is literal in single quotes
+		{"dollar_single_quote", "KEY=$'it\\'s\\x41\\n'\n", true},
 	}
 
 	for _, tc := range cases {
@@ -63,3 +64,36 @@ func TestParseTarget_RejectsNonASCIILeadingWhitespace(t *testing.T) {
 		t.Fatalf("expected EVE-107-101, got %v", err)
 	}
 }
+
+// [EVT-MGU-7] Declaration prefixes survive target parsing and masking
+func TestMaskEnv_PreservesDeclarationPrefix(t *testing.T) {
+	text := "export A=\"s3cret\"\nreadonly B=v4lue\ndeclare -x C='x'\n"
+	elems, err := ParseTarget(text)
+	if err != nil {
+		t.Fatalf("ParseTarget error: %v", err)
+	}
+	if got := elems[2].Assignment.Declaration; got != "declare -x " {
+		t.Fatalf("declaration = %q, want %q", got, "declare -x ")
+	}
+	out, err := MaskEnv(text)
+	if err != nil {
+		t.Fatalf("MaskEnv error: %v", err)
+	}
+	want := "export A=\"******\"\nreadonly B=*****\ndeclare -x C='*'\n"
+	if out != want {
+		t.Fatalf("MaskEnv = %q, want %q", out, want)
+	}
+}
+
+// [EVT-MSU-1] Parameter expansions keep their name, operator and braces
+func TestMaskEnv_PreservesParameterExpansion(t *testing.T) {
+	text := "A=${A:-s3cret\\}x}\nB=\"${B:-pa55word}\"\n"
+	out, err := MaskEnv(text)
+	if err != nil {
+		t.Fatalf("MaskEnv error: %v", err)
+	}
+	want := "A=${A:-s******x}\nB=\"${B:-p******d}\"\n"
+	if out != want {
+		t.Fatalf("MaskEnv = %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,149 @@
+package envseed
+
+// Placeholder scheme registry and the built-in non-pass schemes.
+// This file holds:
+//  - schemeClients: assemble the per-run scheme -> client registry
+//  - rebaseIncludedPaths: make file paths of included templates resolve
+//    relative to the included file
+//  - EnvClient: `<env:NAME>` reads process environment variables
+//  - FileClient: `<file:PATH>` reads local files relative to the template
+// Encrypted-file, remote, and password-manager schemes live in their own files
+// (age.go, sops.go, vault.go, onepassword.go, bitwarden.go); otp.go derives TOTP
+// codes from pass entries.
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"envseed/internal/ast"
+)
+
+// schemeConfig carries the per-run settings of the built-in scheme clients.
+type schemeConfig struct {
+	// baseDir is the template's directory; relative file paths resolve here.
+	baseDir     string
+	ageIdentity string
+}
+
+// schemeClients builds the registry used for one run. Built-in schemes come
+// first; entries in extra replace or add to them.
+func schemeClients(pass PassClient, cfg schemeConfig, extra map[string]SchemeClient) map[string]SchemeClient {
+	clients := map[string]SchemeClient{
+		ast.SchemePass: pass,
+		"env":          &EnvClient{},
+		"file":         &FileClient{BaseDir: cfg.baseDir},
+		"age":          &AgeClient{BaseDir: cfg.baseDir, IdentityFile: cfg.ageIdentity},
+		"sops":         &SopsClient{BaseDir: cfg.baseDir},
+		"vault":        &VaultClient{},
+		"op":           &OnePasswordCommand{},
+		"bw":           &BitwardenCommand{},
+		"otp":          &OTPClient{Source: pass},
+	}
+	for scheme, client := range extra {
+		clients[scheme] = client
+	}
+	return clients
+}
+
+// fileSchemes are the built-in schemes whose PATH names a local file.
+var fileSchemes = map[string]bool{"file": true, "age": true, "sops": true}
+
+// rebaseIncludedPaths rewrites the relative file PATHs of placeholders from
+// included templates so that they resolve against the included template's
+// directory instead of baseDir, the top-level template's directory. A sops
+// PATH keeps its `#key.path` selector. elements is not modified.
+func rebaseIncludedPaths(elements []ast.Element, baseDir string) []ast.Element {
+	out := make([]ast.Element, len(elements))
+	copy(out, elements)
+	for i, el := range out {
+		if el.Type != ast.ElementAssignment || el.Assignment == nil || el.Assignment.File == "" {
+			continue
+		}
+		dir := filepath.Dir(el.Assignment.File)
+		if filepath.Clean(dir) == filepath.Clean(baseDir) {
+			continue
+		}
+		var tokens []ast.ValueToken
+		for j, tok := range el.Assignment.ValueTokens {
+			if tok.Kind != ast.ValuePlaceholder || !fileSchemes[tok.Scheme] {
+				continue
+			}
+			file, selector := tok.Path, ""
+			if tok.Scheme == "sops" {
+				if source, sel, ok := splitDocumentPath(tok.Path); ok {
+					file, selector = source, "#"+sel
+				}
+			}
+			if filepath.IsAbs(file) {
+				continue
+			}
+			if tokens == nil {
+				tokens = append([]ast.ValueToken(nil), el.Assignment.ValueTokens...)
+			}
+			tokens[j].Path = rebasePath(filepath.Join(dir, file), baseDir) + selector
+		}
+		if tokens != nil {
+			assignment := *el.Assignment
+			assignment.ValueTokens = tokens
+			out[i].Assignment = &assignment
+		}
+	}
+	return out
+}
+
+// rebasePath returns path relative to baseDir, or absolute when it cannot be
+// expressed relative to it.
+func rebasePath(path, baseDir string) string {
+	if !filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(baseDir, path); err == nil {
+			return rel
+		}
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// EnvClient implements SchemeClient for `<env:NAME>` placeholders.
+type EnvClient struct {
+	// Lookup defaults to os.LookupEnv.
+	Lookup func(name string) (string, bool)
+}
+
+// Show returns the value of the environment variable NAME.
+func (e *EnvClient) Show(_ context.Context, name string) (string, error) {
+	lookup := e.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	value, ok := lookup(name)
+	if !ok {
+		return "", NewExitError("EVE-104-202", name)
+	}
+	return value, nil
+}
+
+// FileClient implements SchemeClient for `<file:PATH>` placeholders.
+// Relative paths are resolved against BaseDir (the template's directory).
+type FileClient struct {
+	BaseDir string
+}
+
+// Show returns the content of the file at PATH.
+func (f *FileClient) Show(_ context.Context, path string) (string, error) {
+	full := path
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(f.BaseDir, full)
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", NewExitError("EVE-104-203", path).WithErr(err)
+		}
+		return "", NewExitError("EVE-104-102", path).WithErr(err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,227 @@
+package envseed
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"envseed/internal/parser"
+	"envseed/internal/renderer"
+)
+
+func renderWithSchemes(t *testing.T, template string, clients map[string]SchemeClient) (string, error) {
+	t.Helper()
+	elems, err := parser.Parse(template)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	resolver := newSecretResolver(context.Background(), clients)
+	defer resolver.Close()
+	out, err := renderer.RenderElements(elems, resolver)
+	if err != nil {
+		return "", wrapRenderError(err)
+	}
+	return out, nil
+}
+
+func expectExitDetail(t *testing.T, err error, code string) *ExitError {
+	t.Helper()
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("expected ExitError %s, got %v", code, err)
+	}
+	if exitErr.DetailCode != code {
+		t.Fatalf("detail code = %s, want %s", exitErr.DetailCode, code)
+	}
+	return exitErr
+}
+
+// installCommandStub puts script on PATH as name, ahead of the system commands.
+func installCommandStub(t *testing.T, name, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+		t.Fatalf("write stub: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// [EVT-MZU-7]
+func TestSchemeResolverCachesPerSchemeAndPath(t *testing.T) {
+	pass := &fakePass{values: map[string]string{"key": "pass-value"}}
+	env := &fakePass{values: map[string]string{"key": "env-value"}}
+	template := "A=<pass:key|strip_right>\nB=<env:key|strip_right>\nC=<env:key|strip_right><pass:key|strip_right>\n"
+	got, err := renderWithSchemes(t, template, map[string]SchemeClient{"pass": pass, "env": env})
+	if err != nil {
+		t.Fatalf("render error: %v", err)
+	}
+	want := "A=pass-value\nB=env-value\nC=env-valuepass-value\n"
+	if got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+	if pass.calls["key"] != 1 || env.calls["key"] != 1 {
+		t.Fatalf("unexpected calls: pass=%v env=%v", pass.calls, env.calls)
+	}
+}
+
+// [EVT-MZU-7][EVT-MZU-2]
+func TestSchemeResolverUnregisteredScheme(t *testing.T) {
+	_, err := renderWithSchemes(t, "A=<env:HOME>\n", map[string]SchemeClient{"pass": &fakePass{}})
+	expectExitDetail(t, err, "EVE-104-401")
+}
+
+// [EVT-MZU-7][EVT-MUU-1]
+func TestSchemeResolverRejectsNULValues(t *testing.T) {
+	env := &fakePass{values: map[string]string{"BAD": "a\x00b"}}
+	_, err := renderWithSchemes(t, "A=<env:BAD>\n", map[string]SchemeClient{"env": env})
+	expectExitDetail(t, err, "EVE-104-302")
+}
+
+// [EVT-MZU-8]
+func TestEnvClient(t *testing.T) {
+	client := &EnvClient{Lookup: func(name string) (string, bool) {
+		if name == "SET" {
+			return "value", true
+		}
+		return "", false
+	}}
+	got, err := client.Show(context.Background(), "SET")
+	if err != nil || got != "value" {
+		t.Fatalf("Show(SET) = %q, %v", got, err)
+	}
+	_, err = client.Show(context.Background(), "UNSET")
+	expectExitDetail(t, err, "EVE-104-202")
+}
+
+// [EVT-MZU-8]
+func TestRenderErrorPrintsResolverErrorOnce(t *testing.T) {
+	_, err := renderWithSchemes(t, "A=<env:UNSET>\n", map[string]SchemeClient{
+		"env": &EnvClient{Lookup: func(string) (string, bool) { return "", false }},
+	})
+	exitErr := expectExitDetail(t, err, "EVE-104-202")
+	for _, part := range []string{"[EVE-104-202]", "Detail:", "Reference:"} {
+		if n := strings.Count(exitErr.Error(), part); n != 1 {
+			t.Fatalf("%q printed %d times in %q", part, n, exitErr.Error())
+		}
+	}
+}
+
+// [EVT-MZU-8]
+func TestFileClientResolvesRelativeToBaseDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "certs"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "certs", "ca.pem"), []byte("CERT\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	client := &FileClient{BaseDir: dir}
+	got, err := client.Show(context.Background(), "certs/ca.pem")
+	if err != nil {
+		t.Fatalf("Show error: %v", err)
+	}
+	if got != "CERT\n" {
+		t.Fatalf("Show = %q, want raw file content", got)
+	}
+	abs, err := client.Show(context.Background(), filepath.Join(dir, "certs", "ca.pem"))
+	if err != nil || abs != got {
+		t.Fatalf("absolute Show = %q, %v", abs, err)
+	}
+	_, err = client.Show(context.Background(), "missing.pem")
+	expectExitDetail(t, err, "EVE-104-203")
+	_, err = client.Show(context.Background(), "certs")
+	expectExitDetail(t, err, "EVE-104-102")
+}
+
+// [EVT-MGU-8][EVT-MZU-8]
+func TestRebaseIncludedPaths(t *testing.T) {
+	elems, err := parser.Parse("A=<file:ca.pem><sops:s.yaml#db.pw><age:/abs/k.age><pass:p><env:E>\nB=<file:ca.pem>\n")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	elems[0].Assignment.File = filepath.Join("shared", ".envseed.common")
+	elems[1].Assignment.File = filepath.Join("app", ".envseed")
+	got := rebaseIncludedPaths(elems, "app")
+	var paths []string
+	for _, el := range got {
+		for _, tok := range el.Assignment.ValueTokens {
+			paths = append(paths, tok.Path)
+		}
+	}
+	want := []string{
+		filepath.Join("..", "shared", "ca.pem"),
+		filepath.Join("..", "shared", "s.yaml") + "#db.pw",
+		"/abs/k.age", "p", "E",
+		"ca.pem",
+	}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Fatalf("paths = %q, want %q", paths, want)
+	}
+	if elems[0].Assignment.ValueTokens[0].Path != "ca.pem" {
+		t.Fatalf("rebaseIncludedPaths modified its input")
+	}
+}
+
+// [EVT-MGU-8][EVT-MZU-8]
+func TestSyncResolvesFilePathsRelativeToIncludedTemplate(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"app/.envseed":           "#@include ../shared/.envseed.common\nAPP=<file:./app.txt>\n",
+		"app/app.txt":            "app",
+		"shared/.envseed.common": "CA=<file:./ca.txt>\n",
+		"shared/ca.txt":          "shared-ca",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := Sync(context.Background(), SyncOptions{InputPath: filepath.Join(root, "app", ".envseed"), PassClient: &fakePass{}, Quiet: true}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "app", ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if want := "CA=shared-ca\nAPP=app\n"; string(data) != want {
+		t.Fatalf("output = %q, want %q", data, want)
+	}
+}
+
+// [EVT-MZU-7][EVT-MZU-8]
+func TestSyncResolvesBuiltinSchemes(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	template := "TOKEN=<pass:token>\nCA=\"<file:ca.pem|allow_newline>\"\nREGION=<env:REGION>\n"
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ca.pem"), []byte("line1\nline2\n"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	env := &EnvClient{Lookup: func(name string) (string, bool) {
+		return "eu-west-1", name == "REGION"
+	}}
+	if err := Sync(context.Background(), SyncOptions{
+		InputPath:  input,
+		PassClient: &fakePass{values: map[string]string{"token": "abc"}},
+		Schemes:    map[string]SchemeClient{"env": env},
+	}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	want := "TOKEN=abc\nCA=\"line1\nline2\"\nREGION=eu-west-1\n"
+	if string(data) != want {
+		t.Fatalf("output = %q, want %q", string(data), want)
+	}
+}
@@ -3,47 +3,238 @@ package envseed
 import (
 	"context"
 	"strings"
+	"sync"
+
+	"envseed/internal/ast"
 )
 
+// secretCache resolves each (scheme, PATH) at most once per run; for clients
+// that see the whole placeholder, the modifiers and quoting context are part
+// of the key as well. It is safe for
+// concurrent use: concurrent requests for the same key share a single fetch,
+// and the outcome (value or error) is kept for the rest of the run.
 type secretCache struct {
-	client PassClient
-	cache  map[string]secretEntry
+	mu        sync.Mutex
+	clients   map[string]SchemeClient
+	cache     map[secretKey]*secretLoad
+	documents map[secretKey]*documentLoad
+}
+
+type secretKey struct {
+	scheme string
+	path   string
+	// detail is the modifiers and quoting context of the placeholder for
+	// PlaceholderClient schemes, and empty otherwise.
+	detail string
 }
 
 type secretEntry struct {
 	value string
 }
 
-func newSecretCache(client PassClient) *secretCache {
+// secretLoad is one fetch of a (scheme, PATH); done is closed once entry/err
+// are set.
+type secretLoad struct {
+	done  chan struct{}
+	entry secretEntry
+	err   error
+}
+
+// documentLoad is one LoadDocument call of a document SOURCE; done is closed
+// once doc/err are set.
+type documentLoad struct {
+	done chan struct{}
+	doc  any
+	err  error
+}
+
+func newSecretCache(clients map[string]SchemeClient) *secretCache {
 	return &secretCache{
-		client: client,
-		cache:  make(map[string]secretEntry),
+		clients:   clients,
+		cache:     make(map[secretKey]*secretLoad),
+		documents: make(map[secretKey]*documentLoad),
+	}
+}
+
+func (c *secretCache) get(ctx context.Context, scheme, path string) (secretEntry, error) {
+	return c.getPlaceholder(ctx, ast.ValueToken{Kind: ast.ValuePlaceholder, Scheme: scheme, Path: path})
+}
+
+// getPlaceholder resolves the key of tok at most once per run.
+func (c *secretCache) getPlaceholder(ctx context.Context, tok ast.ValueToken) (secretEntry, error) {
+	c.mu.Lock()
+	client, key := c.lookup(tok)
+	return c.load(ctx, client, key, tok)
+}
+
+// load returns the outcome cached for key, fetching it from client first when
+// there is none. The caller holds c.mu; load releases it.
+func (c *secretCache) load(ctx context.Context, client SchemeClient, key secretKey, tok ast.ValueToken) (secretEntry, error) {
+	if load, ok := c.cache[key]; ok {
+		c.mu.Unlock()
+		<-load.done
+		return load.entry, load.err
 	}
+	load := &secretLoad{done: make(chan struct{})}
+	c.cache[key] = load
+	c.mu.Unlock()
+
+	load.entry, load.err = c.fetch(ctx, client, key, tok)
+	close(load.done)
+	return load.entry, load.err
 }
 
-func (c *secretCache) get(ctx context.Context, path string) (secretEntry, error) {
-	if entry, ok := c.cache[path]; ok {
-		return entry, nil
+// fetch retrieves and validates one value from client.
+func (c *secretCache) fetch(ctx context.Context, client SchemeClient, key secretKey, tok ast.ValueToken) (secretEntry, error) {
+	scheme, path := key.scheme, key.path
+	if client == nil {
+		return secretEntry{}, NewExitError("EVE-104-401", scheme)
+	}
+	var raw string
+	var err error
+	switch cl := client.(type) {
+	case DocumentClient:
+		raw, err = selectLeaf(scheme, path, func(source string) (any, error) {
+			return c.document(ctx, scheme, source, cl)
+		})
+	case PlaceholderClient:
+		raw, err = cl.ShowPlaceholder(ctx, tok)
+	case *OTPClient:
+		var entry secretEntry
+		entry, err = c.sourceEntry(ctx, cl.Source, path)
+		if err == nil {
+			raw, err = cl.codeFromEntry(path, entry.value)
+		}
+	default:
+		raw, err = client.Show(ctx, path)
 	}
-	raw, err := c.client.Show(ctx, path)
 	if err != nil {
 		return secretEntry{}, err
 	}
 	if strings.IndexByte(raw, 0) >= 0 {
-		return secretEntry{}, NewExitError("EVE-104-301", path)
+		if scheme == ast.SchemePass {
+			return secretEntry{}, NewExitError("EVE-104-301", path)
+		}
+		return secretEntry{}, NewExitError("EVE-104-302", scheme, path)
 	}
-	entry := secretEntry{value: raw}
-	c.cache[path] = entry
-	return entry, nil
+	return secretEntry{value: raw}, nil
+}
+
+// sourceEntry reads the `pass` entry PATH from source through the (pass, PATH)
+// cache entry, so a template that reads the entry with both `<pass:PATH>` and
+// `<otp:PATH>` decrypts it once.
+func (c *secretCache) sourceEntry(ctx context.Context, source SchemeClient, path string) (secretEntry, error) {
+	tok := ast.ValueToken{Kind: ast.ValuePlaceholder, Scheme: ast.SchemePass, Path: path}
+	c.mu.Lock()
+	return c.load(ctx, source, secretKey{scheme: ast.SchemePass, path: path}, tok)
+}
+
+// key returns the cache key of tok.
+func (c *secretCache) key(tok ast.ValueToken) secretKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, key := c.lookup(tok)
+	return key
 }
 
+// lookup returns the client and the cache key of tok. A PlaceholderClient may
+// answer differently per occurrence, so its key includes the modifiers and
+// quoting context. The caller holds c.mu.
+func (c *secretCache) lookup(tok ast.ValueToken) (SchemeClient, secretKey) {
+	key := secretKey{scheme: tokenScheme(tok), path: tok.Path}
+	client := c.client(key.scheme)
+	if _, ok := client.(PlaceholderClient); ok {
+		var b strings.Builder
+		b.WriteString(contextName(tok.Context))
+		for _, m := range tok.Modifiers {
+			b.WriteByte('|')
+			b.WriteString(m.String())
+		}
+		key.detail = b.String()
+	}
+	return client, key
+}
+
+// client returns the client registered for scheme. Plugin schemes without a
+// registered client are served by their resolver executable. The caller holds
+// c.mu.
+func (c *secretCache) client(scheme string) SchemeClient {
+	if client, ok := c.clients[scheme]; ok && client != nil {
+		return client
+	}
+	if _, ok := ast.PluginName(scheme); ok {
+		client := &PluginClient{Scheme: scheme}
+		c.clients[scheme] = client
+		return client
+	}
+	return nil
+}
+
+// document returns the parsed document for SOURCE, loading it at most once.
+func (c *secretCache) document(ctx context.Context, scheme, source string, client DocumentClient) (any, error) {
+	key := secretKey{scheme: scheme, path: source}
+	c.mu.Lock()
+	if load, ok := c.documents[key]; ok {
+		c.mu.Unlock()
+		<-load.done
+		return load.doc, load.err
+	}
+	load := &documentLoad{done: make(chan struct{})}
+	c.documents[key] = load
+	c.mu.Unlock()
+
+	load.doc, load.err = client.LoadDocument(ctx, source)
+	close(load.done)
+	return load.doc, load.err
+}
+
+// values returns the successfully resolved values by key.
+func (c *secretCache) values() map[secretKey]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[secretKey]string, len(c.cache))
+	for k, load := range c.cache {
+		select {
+		case <-load.done:
+			if load.err == nil {
+				out[k] = load.entry.value
+			}
+		default:
+		}
+	}
+	return out
+}
+
+// clear drops every cached value, including those an AgentClient prefetched.
 func (c *secretCache) clear() {
-	for k, entry := range c.cache {
-		if len(entry.value) > 0 {
-			entry.value = ""
-			c.cache[k] = entry
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, client := range c.clients {
+		if agent, ok := client.(*AgentClient); ok {
+			agent.Close()
+		}
+	}
+	for k, load := range c.cache {
+		select {
+		case <-load.done:
+			load.entry.value = ""
+		default:
 		}
 		delete(c.cache, k)
 	}
 	c.cache = nil
+	for k := range c.documents {
+		delete(c.documents, k)
+	}
+	c.documents = nil
+}
+
+// set records value as the outcome for (scheme, PATH), replacing an earlier
+// outcome. It serves entries created during the run without fetching them.
+func (c *secretCache) set(scheme, path, value string) {
+	load := &secretLoad{done: make(chan struct{}), entry: secretEntry{value: value}}
+	close(load.done)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache[secretKey{scheme: scheme, path: path}] = load
 }
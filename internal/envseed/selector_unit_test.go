@@ -0,0 +1,51 @@
+package envseed
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const multiLineEntry = "s3cr3t pw\r\nUser: alice\r\nurl:https://example.com\r\nnote:  two words\r\n"
+
+// [EVT-MZU-17]
+func TestSyncSelectsPartsOfCachedEntry(t *testing.T) {
+	pass := &fakePass{values: map[string]string{"site": multiLineEntry}}
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	template := "PASSWORD=\"<pass:site|first_line>\"\nUSER=<pass:site|field=user>\nURL=<pass:site|field=URL>\nNOTE=\"<pass:site|field=note>\"\nLINE3=<pass:site|line=3,strip>\n"
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: pass, Quiet: true}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	want := "PASSWORD=\"s3cr3t pw\"\nUSER=alice\nURL=https://example.com\nNOTE=\"two words\"\nLINE3=url:https://example.com\n"
+	if string(data) != want {
+		t.Fatalf("output = %q, want %q", string(data), want)
+	}
+	if n := pass.calls["site"]; n != 1 {
+		t.Fatalf("pass show called %d times, want 1", n)
+	}
+}
+
+// [EVT-MZU-17][EVT-MZU-2]
+func TestSelectorErrors(t *testing.T) {
+	values := map[string]string{"site": "pw\nuser: alice\n"}
+	cases := map[string]string{
+		"V=<pass:site|line=3>\n":                      "EVE-105-801",
+		"V=<pass:site|field=url>\n":                   "EVE-105-802",
+		"V=<pass:site|field=pw>\n":                    "EVE-105-802",
+		"V=<pass:site|first_line,field=user>\n":       "EVE-105-602",
+		"V=<pass:site|first_line,base64,allow_tab>\n": "EVE-105-601",
+	}
+	for template, code := range cases {
+		_, err := renderResultWithPass(t, template, values)
+		expectExitDetail(t, wrapRenderError(err), code)
+	}
+}
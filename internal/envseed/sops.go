@@ -0,0 +1,61 @@
+package envseed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SopsClient implements DocumentClient for `<sops:FILE#key.path>` placeholders.
+// FILE is decrypted with `sops --decrypt` (YAML, JSON, dotenv and INI files
+// alike, converted to JSON); relative paths are resolved against BaseDir (the
+// template's directory).
+type SopsClient struct {
+	BaseDir string
+}
+
+// Show decrypts FILE and returns the leaf selected by key.path.
+func (s *SopsClient) Show(ctx context.Context, path string) (string, error) {
+	return showDocumentValue(ctx, s, "sops", path)
+}
+
+// LoadDocument decrypts FILE and returns its parsed tree.
+func (s *SopsClient) LoadDocument(ctx context.Context, file string) (any, error) {
+	full := file
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(s.BaseDir, full)
+	}
+	if _, err := os.Stat(full); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, NewExitError("EVE-104-203", file).WithErr(err)
+		}
+		return nil, NewExitError("EVE-104-102", file).WithErr(err)
+	}
+	cmd := exec.CommandContext(ctx, "sops", "--decrypt", "--output-type", "json", full)
+	// Connect stdin so that interactive pinentry can receive user input.
+	cmd.Stdin = os.Stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, NewExitError("EVE-104-1", "sops").WithErr(err)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.New(msg)
+		}
+		return nil, NewExitError("EVE-104-104", file).WithErr(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(out))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, NewExitError("EVE-104-601", "sops", file).WithErr(err)
+	}
+	return doc, nil
+}
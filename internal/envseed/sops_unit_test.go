@@ -0,0 +1,120 @@
+package envseed
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubSops stands in for `sops --decrypt --output-type json FILE`: the
+// "encrypted" file already holds the JSON output, a file starting with FAIL
+// fails to decrypt, and every run is logged.
+const stubSops = `#!/bin/sh
+echo "$*" >> "$STUB_SOPS_LOG"
+for last; do :; done
+if [ "$(head -c 4 "$last")" = FAIL ]; then
+  echo "Failed to get the data key required to decrypt the SOPS file." >&2
+  exit 128
+fi
+cat "$last"
+`
+
+func installSopsStub(t *testing.T) string {
+	t.Helper()
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "sops"), []byte(stubSops), 0o755); err != nil {
+		t.Fatalf("write stub: %v", err)
+	}
+	log := filepath.Join(t.TempDir(), "sops.log")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("STUB_SOPS_LOG", log)
+	return log
+}
+
+const sopsDoc = `{"db":{"user":"alice","password":"p@ss word","port":5432,"hosts":["a.example","b.example"],"tls":true,"note":null}}`
+
+// [EVT-MZU-12]
+func TestSyncSopsDecryptsDocumentOnce(t *testing.T) {
+	log := installSopsStub(t)
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "secrets"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secrets", "prod.yaml"), []byte(sopsDoc), 0o600); err != nil {
+		t.Fatalf("write doc: %v", err)
+	}
+	input := filepath.Join(dir, ".envseed")
+	template := strings.Join([]string{
+		"DB_USER=<sops:secrets/prod.yaml#db.user>",
+		"DB_PASSWORD=\"<sops:secrets/prod.yaml#db.password>\"",
+		"DB_PORT=<sops:secrets/prod.yaml#db.port>",
+		"DB_HOST=<sops:secrets/prod.yaml#db.hosts.1>",
+		"DB_TLS=<sops:secrets/prod.yaml#db.tls>",
+		"DB_NOTE=<sops:secrets/prod.yaml#db.note>",
+	}, "\n") + "\n"
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: &fakePass{}, Quiet: true}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	want := "DB_USER=alice\nDB_PASSWORD=\"p@ss word\"\nDB_PORT=5432\nDB_HOST=b.example\nDB_TLS=true\nDB_NOTE=\n"
+	if string(data) != want {
+		t.Fatalf("output = %q, want %q", string(data), want)
+	}
+	logData, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if runs := strings.Count(string(logData), "\n"); runs != 1 {
+		t.Fatalf("sops ran %d times, want 1", runs)
+	}
+}
+
+// [EVT-MZU-12][EVT-MZU-2]
+func TestSopsClientErrors(t *testing.T) {
+	installSopsStub(t)
+	dir := t.TempDir()
+	files := map[string]string{
+		"prod.yaml":   sopsDoc,
+		"locked.yaml": "FAIL",
+		"broken.yaml": "{not json",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	cases := map[string]string{
+		"prod.yaml#db.missing": "EVE-104-204",
+		"prod.yaml#db.hosts.7": "EVE-104-204",
+		"prod.yaml#db.user.x":  "EVE-104-204",
+		"prod.yaml#db":         "EVE-104-602",
+		"prod.yaml":            "EVE-104-603",
+		"prod.yaml#":           "EVE-104-603",
+		"missing.yaml#db.user": "EVE-104-203",
+		"locked.yaml#db.user":  "EVE-104-104",
+		"broken.yaml#db.user":  "EVE-104-601",
+	}
+	for path, code := range cases {
+		_, err := renderWithSchemes(t, "V=<sops:"+path+">\n", map[string]SchemeClient{"sops": &SopsClient{BaseDir: dir}})
+		expectExitDetail(t, err, code)
+	}
+}
+
+// [EVT-MZU-12]
+func TestSopsClientMissingBinary(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "prod.yaml"), []byte(sopsDoc), 0o600); err != nil {
+		t.Fatalf("write doc: %v", err)
+	}
+	t.Setenv("PATH", t.TempDir())
+	_, err := (&SopsClient{BaseDir: dir}).Show(context.Background(), "prod.yaml#db.user")
+	expectExitDetail(t, err, "EVE-104-1")
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 
 	"envseed/internal/parser"
 	"envseed/internal/renderer"
@@ -15,6 +16,13 @@ import (
 func Sync(ctx context.Context, opts SyncOptions) error {
 	// NOTE: input selection is handled by CLI (0/1 args). I/O classification follows EVE-102 bands.
 
+	if opts.GenerateMissing && opts.DryRun {
+		return NewExitError("EVE-101-3")
+	}
+	if err := checkProfileName(opts.Profile); err != nil {
+		return err
+	}
+
 	passClient := opts.PassClient
 	if passClient == nil {
 		passClient = &PassCommand{}
@@ -50,19 +58,37 @@ func Sync(ctx context.Context, opts SyncOptions) error {
 		return NewExitError("EVE-102-202", opts.InputPath).WithErr(rerr)
 	}
 
-	targetPath, err := resolveOutputPath(opts.InputPath, opts.OutputPath)
+	targetPath, err := resolveOutputPath(opts.InputPath, opts.OutputPath, opts.Profile)
 	if err != nil {
 		return err
 	}
 
 	source := string(data)
-	elements, err := parser.Parse(source)
+	elements, err := parser.ParseFile(opts.InputPath, source)
 	if err != nil {
 		return wrapParseError(err)
 	}
+	elements, err = applyProfile(elements, opts.Profile)
+	if err != nil {
+		return err
+	}
+	elements = rebaseIncludedPaths(elements, filepath.Dir(opts.InputPath))
 
-	resolver := newPassResolver(ctx, passClient)
+	resolver := newSecretResolver(ctx, schemeClients(passClient, schemeConfig{
+		baseDir:     filepath.Dir(opts.InputPath),
+		ageIdentity: opts.AgeIdentity,
+	}, opts.Schemes))
 	defer resolver.Close()
+	resolver.Prefetch(elements, jobCount(opts.Jobs))
+	if opts.GenerateMissing {
+		created, err := generateMissing(ctx, elements, resolver, passClient)
+		if !opts.Quiet {
+			_ = writeGenerated(stderr, created)
+		}
+		if err != nil {
+			return err
+		}
+	}
 
 	rendered, err := renderer.RenderElements(elements, resolver)
 	if err != nil {
@@ -79,6 +105,9 @@ func Sync(ctx context.Context, opts SyncOptions) error {
 		if _, err := fmt.Fprintf(stdout, "target: %s\n%s", targetPath, redacted); err != nil {
 			return NewExitError("EVE-106-401").WithErr(err)
 		}
+		if err := writeFallbacks(stdout, "fallback: ", resolver.Fallbacks()); err != nil {
+			return NewExitError("EVE-106-401").WithErr(err)
+		}
 		return nil
 	}
 
@@ -86,6 +115,10 @@ func Sync(ctx context.Context, opts SyncOptions) error {
 		return err
 	}
 
+	if !opts.Quiet {
+		_ = writeFallbacks(stderr, "warning: ", resolver.Fallbacks())
+	}
+
 	if err := writeOutput(targetPath, []byte(rendered), opts.Quiet, opts.Force, stderr); err != nil {
 		return err
 	}
@@ -309,3 +309,141 @@ func TestSyncMessageFormat(t *testing.T) {
 		t.Fatalf("expected unchanged message, got %q", got)
 	}
 }
+
+// [EVT-MGU-8]
+func TestSyncRendersIncludedTemplates(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "app")
+	shared := filepath.Join(root, "shared")
+	for _, dir := range []string{app, shared} {
+		if err := os.Mkdir(dir, 0o700); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	input := filepath.Join(app, ".envseed")
+	if err := os.WriteFile(input, []byte("APP=<pass:app/key>\n#@include ../shared/.envseed.common\nMODE=dev\n"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	common := filepath.Join(shared, ".envseed.common")
+	if err := os.WriteFile(common, []byte("# shared\nOTEL=\"<pass:shared/otel>\"\n"), 0o600); err != nil {
+		t.Fatalf("write include: %v", err)
+	}
+	pass := &fakePass{values: map[string]string{"app/key": "k1", "shared/otel": "o1"}}
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: pass, Quiet: true}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(app, ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if want := "APP=k1\n# shared\nOTEL=\"o1\"\nMODE=dev\n"; string(data) != want {
+		t.Fatalf("output = %q, want %q", data, want)
+	}
+
+	pass.values["shared/otel"] = "o2"
+	var stdout bytes.Buffer
+	res, err := Diff(context.Background(), DiffOptions{InputPath: input, PassClient: pass, Stdout: &stdout})
+	if err != nil || !res.Changed || !strings.Contains(stdout.String(), "+OTEL=") {
+		t.Fatalf("Diff() = %+v, %v; stdout = %q", res, err, stdout.String())
+	}
+
+	pass.values["shared/otel"] = "o\x01"
+	err = Sync(context.Background(), SyncOptions{InputPath: input, PassClient: pass, Quiet: true, Force: true})
+	exitErr := expectExitDetail(t, err, "EVE-105-203")
+	if !strings.Contains(exitErr.Error(), common+":2:7:") {
+		t.Fatalf("error = %q, want the included file position", exitErr.Error())
+	}
+}
+
+// [EVT-MGU-9][EVT-BIU-3]
+func TestSyncSelectsProfile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	template := "#@vars dev prefix=myapp/dev\n#@vars prod prefix=myapp/prod\nDB_PASSWORD=<pass:{{prefix}}/db/password>\nAPP_ENV=<pass:{{profile}}/name>\n"
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	pass := &fakePass{values: map[string]string{
+		"myapp/dev/db/password":  "dev-secret",
+		"myapp/prod/db/password": "prod-secret",
+		"dev/name":               "development",
+		"prod/name":              "production",
+	}}
+	for _, profile := range []string{"dev", "prod"} {
+		if err := Sync(context.Background(), SyncOptions{InputPath: input, Profile: profile, PassClient: pass, Quiet: true}); err != nil {
+			t.Fatalf("Sync(%s) error = %v", profile, err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".env.prod"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if want := "DB_PASSWORD=prod-secret\nAPP_ENV=production\n"; string(data) != want {
+		t.Fatalf("output = %q, want %q", data, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".env.dev")); err != nil {
+		t.Fatalf("dev output: %v", err)
+	}
+
+	err = Sync(context.Background(), SyncOptions{InputPath: input, Profile: "staging", PassClient: pass, Quiet: true})
+	expectExitDetail(t, err, "EVE-101-15")
+	err = Sync(context.Background(), SyncOptions{InputPath: input, Profile: "../prod", PassClient: pass, Quiet: true})
+	expectExitDetail(t, err, "EVE-101-14")
+	err = Sync(context.Background(), SyncOptions{InputPath: input, PassClient: pass, Quiet: true})
+	expectExitDetail(t, err, "EVE-103-11")
+}
+
+// [EVT-MGU-8]
+func TestSyncIncludedTemplateWithoutFinalNewline(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	if err := os.WriteFile(input, []byte("#@include common\nB=<pass:b>\n"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "common"), []byte("A=1"), 0o600); err != nil {
+		t.Fatalf("write include: %v", err)
+	}
+	pass := &fakePass{values: map[string]string{"b": "2"}}
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: pass, Quiet: true}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if want := "A=1\nB=2\n"; string(data) != want {
+		t.Fatalf("output = %q, want %q", data, want)
+	}
+}
+
+// [EVT-MGU-8][EVT-MZU-19]
+func TestSyncLocatesResolverErrorsInIncludedTemplates(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	if err := os.WriteFile(input, []byte("X=1\n#@include common\n"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	common := filepath.Join(dir, "common")
+	if err := os.WriteFile(common, []byte("OPT=<pass:opt|optional>\nA=<pass:missing>\n"), 0o600); err != nil {
+		t.Fatalf("write include: %v", err)
+	}
+	pass := &fakePass{errs: map[string]error{
+		"opt":     NewExitError("EVE-104-201", "pass", "opt"),
+		"missing": NewExitError("EVE-104-201", "pass", "missing"),
+	}}
+	var stderr bytes.Buffer
+	err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: pass, Stderr: &stderr})
+	exitErr := expectExitDetail(t, err, "EVE-104-201")
+	if want := common + `:2:3: pass entry "missing" not found`; !strings.Contains(exitErr.Error(), want) {
+		t.Fatalf("error = %q, want it to contain %q", exitErr.Error(), want)
+	}
+
+	delete(pass.errs, "missing")
+	pass.values = map[string]string{"missing": "a"}
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: pass, Stderr: &stderr}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if want := "warning: " + common + ":1:5: OPT: <pass:opt> not found; using empty value\n"; !strings.HasPrefix(stderr.String(), want) {
+		t.Fatalf("stderr = %q, want %q", stderr.String(), want)
+	}
+}
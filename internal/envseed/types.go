@@ -3,8 +3,13 @@ package envseed
 import (
 	"context"
 	"io"
+	"time"
 )
 
+// DefaultJobs is the number of placeholders resolved concurrently when no
+// job count is configured.
+const DefaultJobs = 4
+
 // SyncOptions configure the sync subcommand.
 type SyncOptions struct {
 	InputPath  string
@@ -12,8 +17,23 @@ type SyncOptions struct {
 	Force      bool
 	DryRun     bool
 	Quiet      bool
+	// GenerateMissing creates missing `<pass:...>` entries with random
+	// values before rendering (see generateMissing).
+	GenerateMissing bool
+	// Profile selects the `#@vars` profile whose variables fill `{{NAME}}`
+	// references in placeholder paths; the derived output path then ends in
+	// `.<profile>`.
+	Profile string
+
+	// AgeIdentity is the identity file for `<age:...>`; it defaults to
+	// $ENVSEED_AGE_IDENTITY.
+	AgeIdentity string
+	// Jobs bounds the number of placeholders resolved concurrently; zero
+	// selects DefaultJobs.
+	Jobs int
 
 	PassClient PassClient
+	Schemes    map[string]SchemeClient
 	Stdout     io.Writer
 	Stderr     io.Writer
 }
@@ -22,8 +42,20 @@ type SyncOptions struct {
 type DiffOptions struct {
 	InputPath  string
 	OutputPath string
+	// Quiet suppresses the fallback warnings on Stderr.
+	Quiet bool
+	// Profile selects the `#@vars` profile, as in SyncOptions.
+	Profile string
+
+	// AgeIdentity is the identity file for `<age:...>`; it defaults to
+	// $ENVSEED_AGE_IDENTITY.
+	AgeIdentity string
+	// Jobs bounds the number of placeholders resolved concurrently; zero
+	// selects DefaultJobs.
+	Jobs int
 
 	PassClient PassClient
+	Schemes    map[string]SchemeClient
 	Stdout     io.Writer
 	Stderr     io.Writer
 }
@@ -33,12 +65,101 @@ type DiffResult struct {
 	Changed bool
 }
 
+// ImportOptions configure the import subcommand.
+type ImportOptions struct {
+	// InputPath is the existing .env file; OutputPath is the template to
+	// write and defaults to `.envseed` next to it.
+	InputPath  string
+	OutputPath string
+	// Prefix is the pass folder the values are stored under, one entry per
+	// variable (PREFIX/NAME).
+	Prefix string
+	// Keep lists name patterns (path.Match syntax) whose values stay literal.
+	Keep  []string
+	Force bool
+	Quiet bool
+
+	PassClient PassClient
+	Stderr     io.Writer
+}
+
+// PushOptions configure the push subcommand.
+type PushOptions struct {
+	// InputPath is the template; the edited .env is resolved from it and
+	// OutputPath like the output of sync.
+	InputPath  string
+	OutputPath string
+	// Profile selects the `#@vars` profile, as in SyncOptions.
+	Profile string
+	// Yes skips the confirmation prompt; DryRun stops after the summary.
+	Yes    bool
+	DryRun bool
+	Quiet  bool
+
+	// AgeIdentity is the identity file for `<age:...>`; it defaults to
+	// $ENVSEED_AGE_IDENTITY.
+	AgeIdentity string
+	// Jobs bounds the number of placeholders resolved concurrently; zero
+	// selects DefaultJobs.
+	Jobs int
+
+	PassClient PassClient
+	Schemes    map[string]SchemeClient
+	// Stdin answers the confirmation prompt; it defaults to os.Stdin, which
+	// must then be a terminal.
+	Stdin  io.Reader
+	Stderr io.Writer
+}
+
+// AgentOptions configure the agent subcommand.
+type AgentOptions struct {
+	// Socket defaults to AgentSocketPath().
+	Socket string
+	// TTL is how long a value is kept after it is stored; zero selects
+	// DefaultAgentTTL. MaxUses drops a value after that many reads; zero
+	// means unlimited.
+	TTL     time.Duration
+	MaxUses int
+	Quiet   bool
+
+	Stderr io.Writer
+}
+
 // ValidateOptions configure the validate subcommand.
 type ValidateOptions struct {
 	InputPath string
+	// Profile limits the check to one profile; by default every profile
+	// declared with `#@vars` is checked.
+	Profile string
 }
 
 // PassClient retrieves secrets from pass.
 type PassClient interface {
 	Show(ctx context.Context, path string) (string, error)
 }
+
+// Inserter is implemented by PassClient backends that can add entries to the
+// store. Insert creates PATH holding value and fails if it already exists.
+type Inserter interface {
+	Insert(ctx context.Context, path, value string) error
+}
+
+// Updater is implemented by PassClient backends that can replace entries.
+// Update stores value as PATH, replacing an existing entry.
+type Updater interface {
+	Update(ctx context.Context, path, value string) error
+}
+
+// Prefetcher is implemented by scheme clients that retrieve several entries
+// more cheaply together than one at a time. Sync and Diff pass it every PATH of
+// the client's scheme, in template order, before rendering. Per-entry failures
+// are reported by the subsequent Show calls.
+type Prefetcher interface {
+	Prefetch(ctx context.Context, paths []string)
+}
+
+// SchemeClient retrieves raw values for placeholders of a single scheme
+// (e.g. `<env:...>`). PassClient implementations satisfy it.
+type SchemeClient interface {
+	Show(ctx context.Context, path string) (string, error)
+}
@@ -15,6 +15,9 @@ func Validate(_ context.Context, opts ValidateOptions) error {
 		// CLI handles input selection; treat empty as internal misuse
 		return NewExitError("EVE-102-203", "<empty>")
 	}
+	if err := checkProfileName(opts.Profile); err != nil {
+		return err
+	}
 
 	if info, statErr := os.Lstat(opts.InputPath); statErr != nil {
 		code := classifyStatDetail(statErr)
@@ -35,8 +38,27 @@ func Validate(_ context.Context, opts ValidateOptions) error {
 		return NewExitError("EVE-102-202", opts.InputPath).WithErr(rerr)
 	}
 
-	if _, err := parser.Parse(string(data)); err != nil {
+	elements, err := parser.ParseFile(opts.InputPath, string(data))
+	if err != nil {
 		return wrapParseError(err)
 	}
+	if opts.Profile != "" {
+		_, err := applyProfile(elements, opts.Profile)
+		return err
+	}
+	// Without --profile, check the template for every declared profile;
+	// a template without profiles must not reference template variables.
+	profiles, err := parser.Profiles(elements)
+	if err != nil {
+		return wrapParseError(err)
+	}
+	if len(profiles) == 0 {
+		profiles = []string{""}
+	}
+	for _, profile := range profiles {
+		if _, err := applyProfile(elements, profile); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -29,3 +30,24 @@ func TestValidateParseError(t *testing.T) {
 		t.Fatalf("exit code = %d, want %d", exitErr.Code, ExitTemplateParse)
 	}
 }
+
+// [EVT-MGU-9]
+func TestValidateChecksEveryProfile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	template := "#@vars dev prefix=myapp/dev\n#@vars prod\nDB_PASSWORD=<pass:{{prefix}}/db/password>\n"
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	if err := Validate(context.Background(), ValidateOptions{InputPath: input, Profile: "dev"}); err != nil {
+		t.Fatalf("Validate(dev) error = %v", err)
+	}
+	err := Validate(context.Background(), ValidateOptions{InputPath: input})
+	exitErr := expectExitDetail(t, err, "EVE-103-10")
+	if !strings.Contains(exitErr.Error(), `undefined template variable "prefix" in profile "prod"`) {
+		t.Fatalf("error = %q, want the failing profile", exitErr.Error())
+	}
+}
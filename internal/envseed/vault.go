@@ -0,0 +1,130 @@
+package envseed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VaultClient implements DocumentClient for `<vault:PATH#field>` placeholders
+// against HashiCorp Vault KV secrets engines. PATH is the API path below
+// `/v1/` (e.g. `secret/data/app` for KV v2, `kv/app` for KV v1); the secret's
+// key/value map is the document that `#field` selects from.
+type VaultClient struct {
+	// Addr defaults to $VAULT_ADDR.
+	Addr string
+	// Token defaults to $VAULT_TOKEN, then the content of ~/.vault-token.
+	Token string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Show reads PATH and returns the field selected after '#'.
+func (v *VaultClient) Show(ctx context.Context, path string) (string, error) {
+	return showDocumentValue(ctx, v, "vault", path)
+}
+
+// LoadDocument reads the secret at PATH. KV v2 responses are unwrapped so
+// that both engine versions expose the secret's own key/value map.
+func (v *VaultClient) LoadDocument(ctx context.Context, path string) (any, error) {
+	addr := v.Addr
+	if addr == "" {
+		addr = os.Getenv("VAULT_ADDR")
+	}
+	if addr == "" {
+		return nil, NewExitError("EVE-104-404")
+	}
+	token, err := v.token()
+	if err != nil {
+		return nil, err
+	}
+	endpoint := strings.TrimRight(addr, "/") + "/v1/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, NewExitError("EVE-104-105", path).WithErr(err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	client := v.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, NewExitError("EVE-104-105", path).WithErr(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, NewExitError("EVE-104-105", path).WithErr(err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusForbidden:
+		return nil, NewExitError("EVE-104-501", "vault", path).WithErr(vaultResponseError(resp.Status, body))
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, NewExitError("EVE-104-205", path).WithErr(vaultResponseError(resp.Status, body))
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, NewExitError("EVE-104-105", path).WithErr(vaultResponseError(resp.Status, body))
+	}
+	var payload struct {
+		Data map[string]any `json:"data"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&payload); err != nil {
+		return nil, NewExitError("EVE-104-601", "vault", path).WithErr(err)
+	}
+	if payload.Data == nil {
+		return nil, NewExitError("EVE-104-601", "vault", path).WithErr(errors.New("response has no data"))
+	}
+	if inner, ok := payload.Data["data"].(map[string]any); ok {
+		if _, v2 := payload.Data["metadata"]; v2 {
+			return inner, nil
+		}
+	}
+	return payload.Data, nil
+}
+
+func (v *VaultClient) token() (string, error) {
+	if v.Token != "" {
+		return v.Token, nil
+	}
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	home, err := os.UserHomeDir()
+	if err == nil {
+		data, readErr := os.ReadFile(filepath.Join(home, ".vault-token"))
+		if readErr == nil && strings.TrimSpace(string(data)) != "" {
+			return strings.TrimSpace(string(data)), nil
+		}
+		if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+			err = readErr
+		}
+	}
+	exitErr := NewExitError("EVE-104-405")
+	if err != nil {
+		return "", exitErr.WithErr(err)
+	}
+	return "", exitErr
+}
+
+// vaultResponseError summarizes a failed response using Vault's `errors` list.
+func vaultResponseError(status string, body []byte) error {
+	var payload struct {
+		Errors []string `json:"errors"`
+	}
+	if json.Unmarshal(body, &payload) == nil && len(payload.Errors) > 0 {
+		return fmt.Errorf("%s: %s", status, strings.Join(payload.Errors, "; "))
+	}
+	return errors.New(status)
+}
@@ -0,0 +1,149 @@
+package envseed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const vaultTestToken = "s.test-token"
+
+// newVaultStub serves a KV v1 mount at `kv/` and a KV v2 mount at `secret/`.
+// Requests without the expected token are denied; every request is counted.
+func newVaultStub(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("X-Vault-Token") != vaultTestToken {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/app":
+			_, _ = w.Write([]byte(`{"data":{"password":"v1 pass","port":5432}}`))
+		case "/v1/secret/data/app":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"p@ss$word","nested":{"user":"alice"}},"metadata":{"version":3}}}`))
+		case "/v1/secret/data/broken":
+			_, _ = w.Write([]byte(`{not json`))
+		case "/v1/secret/data/sealed":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"errors":["Vault is sealed"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+// [EVT-MZU-13]
+func TestSyncResolvesVaultKV(t *testing.T) {
+	srv, requests := newVaultStub(t)
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", vaultTestToken)
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".envseed")
+	template := strings.Join([]string{
+		"V1=<vault:kv/app#password>",
+		"PORT=<vault:kv/app#port>",
+		"V2=<vault:secret/data/app#password>",
+		"USER=<vault:secret/data/app#nested.user>",
+	}, "\n") + "\n"
+	if err := os.WriteFile(input, []byte(template), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	if err := Sync(context.Background(), SyncOptions{InputPath: input, PassClient: &fakePass{}, Quiet: true}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if want := "V1=v1\\ pass\nPORT=5432\nV2=p@ss\\$word\nUSER=alice\n"; string(data) != want {
+		t.Fatalf("output = %q, want %q", string(data), want)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("vault received %d requests, want 2", got)
+	}
+}
+
+// [EVT-MZU-13][EVT-MZU-2]
+func TestVaultClientErrors(t *testing.T) {
+	srv, _ := newVaultStub(t)
+	client := &VaultClient{Addr: srv.URL, Token: vaultTestToken}
+	for path, code := range map[string]string{
+		"secret/data/missing#password": "EVE-104-205",
+		"secret/data/sealed#password":  "EVE-104-105",
+		"secret/data/broken#password":  "EVE-104-601",
+		"secret/data/app#missing":      "EVE-104-204",
+		"secret/data/app#nested":       "EVE-104-602",
+		"secret/data/app":              "EVE-104-603",
+	} {
+		_, err := renderWithSchemes(t, "V=<vault:"+path+">\n", map[string]SchemeClient{"vault": client})
+		expectExitDetail(t, err, code)
+	}
+
+	_, err := (&VaultClient{Addr: srv.URL, Token: "s.wrong"}).Show(context.Background(), "secret/data/app#password")
+	exitErr := expectExitDetail(t, err, "EVE-104-501")
+	if !strings.Contains(exitErr.Error(), "permission denied") {
+		t.Fatalf("EVE-104-501 does not carry the vault message: %v", exitErr)
+	}
+	_, err = (&VaultClient{Addr: "http://127.0.0.1:1", Token: vaultTestToken}).Show(context.Background(), "kv/app#password")
+	expectExitDetail(t, err, "EVE-104-105")
+}
+
+// [EVT-MZU-13]
+func TestVaultClientConfiguration(t *testing.T) {
+	srv, _ := newVaultStub(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("VAULT_TOKEN", "")
+
+	t.Setenv("VAULT_ADDR", "")
+	_, err := (&VaultClient{}).Show(context.Background(), "kv/app#password")
+	expectExitDetail(t, err, "EVE-104-404")
+
+	t.Setenv("VAULT_ADDR", srv.URL)
+	_, err = (&VaultClient{}).Show(context.Background(), "kv/app#password")
+	expectExitDetail(t, err, "EVE-104-405")
+
+	if err := os.WriteFile(filepath.Join(home, ".vault-token"), []byte(vaultTestToken+"\n"), 0o600); err != nil {
+		t.Fatalf("write token file: %v", err)
+	}
+	got, err := (&VaultClient{}).Show(context.Background(), "kv/app#password")
+	if err != nil || got != "v1 pass" {
+		t.Fatalf("token file: Show = %q, %v", got, err)
+	}
+}
+
+// [EVT-MZU-13]
+func TestVaultClientHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := (&VaultClient{Addr: srv.URL, Token: vaultTestToken}).Show(ctx, "kv/app#password")
+	exitErr := expectExitDetail(t, err, "EVE-104-105")
+	if !errors.Is(exitErr, context.DeadlineExceeded) {
+		t.Fatalf("EVE-104-105 does not wrap the context error: %v", exitErr)
+	}
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"envseed/internal/ast"
+)
+
+// IncludeDirective starts a whole-line comment that ParseFile replaces with
+// the elements of another template: `#@include ../shared/.envseed.common`.
+const IncludeDirective = "#@include"
+
+// MaxIncludeDepth bounds how deeply `#@include` directives may nest.
+const MaxIncludeDepth = 8
+
+// ParseFile parses src, the content of the template at path, and replaces
+// each `#@include PATH` directive with the elements of PATH, resolved relative
+// to the directory of the including file, so rendering follows the include
+// position. Assignments and errors carry the file they come from; a file may
+// be included more than once, but not from within itself.
+func ParseFile(path, src string) ([]ast.Element, error) {
+	return parseFile(path, src, nil)
+}
+
+func parseFile(path, src string, chain []string) ([]ast.Element, error) {
+	elems, err := Parse(src)
+	if err != nil {
+		if perr, ok := err.(*ParseError); ok {
+			perr.File = path
+		}
+		return nil, err
+	}
+	key := includeKey(path)
+	chain = append(chain, key)
+
+	var out []ast.Element
+	for _, el := range elems {
+		if el.Type == ast.ElementAssignment && el.Assignment != nil {
+			el.Assignment.File = path
+		}
+		if _, _, perr := parseVarsDirective(el); perr != nil {
+			perr.File = path
+			return nil, perr
+		}
+		target, column, ok := includeTarget(el)
+		if !ok {
+			out = append(out, el)
+			continue
+		}
+		fail := func(code, message string, args ...any) *ParseError {
+			perr := newParseError(el.Line, column, code, message, args...)
+			perr.File = path
+			return perr
+		}
+		if target == "" {
+			return nil, fail("EVE-103-8", "missing path in "+IncludeDirective+" directive")
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		for _, seen := range chain {
+			if seen == includeKey(target) {
+				return nil, fail("EVE-103-6", fmt.Sprintf("include cycle through %s", target), target)
+			}
+		}
+		if len(chain) > MaxIncludeDepth {
+			return nil, fail("EVE-103-7", fmt.Sprintf("includes nested deeper than %d levels", MaxIncludeDepth), MaxIncludeDepth)
+		}
+		data, err := os.ReadFile(target)
+		if err != nil {
+			return nil, fail("EVE-103-5", fmt.Sprintf("cannot read included template: %v", err), target)
+		}
+		included, err := parseFile(target, string(data), chain)
+		if err != nil {
+			return nil, err
+		}
+		if len(included) > 0 && el.HasTrailingNewline {
+			terminateLine(&included[len(included)-1])
+		}
+		out = append(out, included...)
+	}
+	return out, nil
+}
+
+// includeTarget returns the path of an include directive element and the
+// column it starts at.
+func includeTarget(el ast.Element) (string, int, bool) {
+	if el.Type != ast.ElementComment {
+		return "", 0, false
+	}
+	text := strings.TrimRight(el.Text, "\r")
+	body := strings.TrimLeft(text, " \t")
+	rest, ok := strings.CutPrefix(body, IncludeDirective)
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return "", 0, false
+	}
+	target := strings.Trim(rest, " \t")
+	column := len(text) - len(strings.TrimLeft(rest, " \t")) + 1
+	return target, column, true
+}
+
+// terminateLine ends el with a newline. The last line of an included template
+// may lack one; the line after the directive must not be joined onto it.
+func terminateLine(el *ast.Element) {
+	switch el.Type {
+	case ast.ElementBlank:
+		if !el.HasTrailingNewline {
+			el.Text += "\n"
+		}
+	case ast.ElementAssignment:
+		el.Assignment.HasTrailingNewline = true
+	}
+	el.HasTrailingNewline = true
+}
+
+// includeKey identifies a template file for cycle detection.
+func includeKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
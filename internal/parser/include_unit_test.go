@@ -0,0 +1,144 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"envseed/internal/parser"
+)
+
+// writeTemplates writes files (relative path → content) below a new
+// directory and returns it.
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+// parseTemplateFile parses the template name below dir with ParseFile.
+func parseTemplateFile(t *testing.T, dir, name string) ([]Element, error) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return parser.ParseFile(path, string(data))
+}
+
+// [EVT-MGU-8]
+func TestParseFile_IncludesAtDirectivePosition(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"app/.envseed":           "FIRST=1\n  #@include ../shared/.envseed.common\nLAST=<pass:app/last>\n",
+		"shared/.envseed.common": "# shared\nOTEL_KEY=<pass:shared/otel>\n#@include nested.env\n",
+		"shared/nested.env":      "NESTED=2\n",
+	})
+	elems, err := parseTemplateFile(t, dir, "app/.envseed")
+	if err != nil {
+		t.Fatalf("ParseFile error: %v", err)
+	}
+	text, err := renderElementsText(elems)
+	if err != nil {
+		t.Fatalf("renderElementsText error: %v", err)
+	}
+	want := "FIRST=1\n# shared\nOTEL_KEY=<pass:shared/otel>\nNESTED=2\nLAST=<pass:app/last>\n"
+	if text != want {
+		t.Fatalf("combined template = %q, want %q", text, want)
+	}
+	var files []string
+	for _, el := range elems {
+		if el.Type == ElementAssignment {
+			files = append(files, filepath.Base(el.Assignment.File)+":"+el.Assignment.Name)
+		}
+	}
+	if got := strings.Join(files, " "); got != ".envseed:FIRST .envseed.common:OTEL_KEY nested.env:NESTED .envseed:LAST" {
+		t.Fatalf("assignment files = %s", got)
+	}
+}
+
+// [EVT-MGU-8]
+func TestParseFile_IncludedFileWithoutFinalNewline(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"assignment", map[string]string{"a": "#@include b\nB=2\n", "b": "A=1"}, "A=1\nB=2\n"},
+		{"comment", map[string]string{"a": "#@include b\nB=2\n", "b": "A=1\n# tail"}, "A=1\n# tail\nB=2\n"},
+		{"directive on last line", map[string]string{"a": "B=2\n#@include b", "b": "A=1"}, "B=2\nA=1"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeTemplates(t, tc.files)
+			elems, err := parseTemplateFile(t, dir, "a")
+			if err != nil {
+				t.Fatalf("ParseFile error: %v", err)
+			}
+			text, err := renderElementsText(elems)
+			if err != nil {
+				t.Fatalf("renderElementsText error: %v", err)
+			}
+			if text != tc.want {
+				t.Fatalf("combined template = %q, want %q", text, tc.want)
+			}
+		})
+	}
+}
+
+// [EVT-MGU-8]
+func TestParseFile_IncludeErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		code  string
+		want  string
+	}{
+		{"missing", map[string]string{"a": "#@include missing.env\n"}, "EVE-103-5", "a:1:11: "},
+		{"cycle", map[string]string{"a": "X=1\n#@include b\n", "b": "#@include a\n"}, "EVE-103-6", "b:1:11: "},
+		{"self", map[string]string{"a": "#@include ./a\n"}, "EVE-103-6", "a:1:11: "},
+		{"empty", map[string]string{"a": "#@include  \n"}, "EVE-103-8", "a:1:"},
+		{"syntax", map[string]string{"a": "#@include b\n", "b": "OK=1\nBROKEN=\"x\n"}, "EVE-103-401", "b:3:1: "},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeTemplates(t, tc.files)
+			_, err := parseTemplateFile(t, dir, "a")
+			perr := expectParseError(t, err, tc.code)
+			if got := strings.TrimPrefix(perr.Error(), dir+string(filepath.Separator)); !strings.HasPrefix(got, tc.want) {
+				t.Fatalf("error = %q, want prefix %q", got, tc.want)
+			}
+		})
+	}
+
+	files := map[string]string{}
+	for i := 0; i <= parser.MaxIncludeDepth; i++ {
+		files[string(rune('a'+i))] = "#@include " + string(rune('b'+i)) + "\n"
+	}
+	files[string(rune('a'+parser.MaxIncludeDepth+1))] = "X=1\n"
+	dir := writeTemplates(t, files)
+	_, err := parseTemplateFile(t, dir, "a")
+	expectParseError(t, err, "EVE-103-7")
+}
+
+// [EVT-MGU-8]
+func TestParseFile_DirectiveLookalikesStayComments(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{"a": "#@included\n# @include b\nX=1 #@include b\n"})
+	elems, err := parseTemplateFile(t, dir, "a")
+	if err != nil {
+		t.Fatalf("ParseFile error: %v", err)
+	}
+	if len(elems) != 3 || elems[0].Type != ElementComment || elems[1].Type != ElementComment {
+		t.Fatalf("elements = %#v", elems)
+	}
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -10,6 +11,8 @@ import (
 import "envseed/internal/ast"
 
 type ParseError struct {
+	// File is the template the error occurred in; set by ParseFile.
+	File       string
 	Line       int
 	Column     int
 	Msg        string
@@ -18,6 +21,9 @@ type ParseError struct {
 }
 
 func (e *ParseError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+	}
 	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
 }
 
@@ -35,6 +41,8 @@ type parseIssue struct {
 	detailCode string
 	message    string
 	detailArgs []any
+	// column, when non-zero, overrides the column of the enclosing token.
+	column int
 }
 
 func newParseIssue(code, message string, args ...any) *parseIssue {
@@ -211,6 +219,10 @@ func (p *parser) consumeAssignment() (ast.Element, error) {
 		leadingWhitespace = p.src[startPos:s.pos]
 	}
 
+	declaration, err := scanDeclaration(&s)
+	if err != nil {
+		return ast.Element{}, err
+	}
 	name, op, err := scanAssignmentName(&s)
 	if err != nil {
 		return ast.Element{}, err
@@ -233,6 +245,7 @@ func (p *parser) consumeAssignment() (ast.Element, error) {
 		Name:               name,
 		Operator:           op,
 		LeadingWhitespace:  leadingWhitespace,
+		Declaration:        declaration,
 		Raw:                raw,
 		ValueTokens:        tokens,
 		TrailingComment:    trailingComment,
@@ -250,6 +263,61 @@ func (p *parser) consumeAssignment() (ast.Element, error) {
 	}, nil
 }
 
+// declarationKeywords are the builtins accepted before an assignment name.
+var declarationKeywords = []string{"export", "readonly", "declare"}
+
+// scanDeclaration consumes an `export`, `readonly` or `declare -x` prefix and
+// the whitespace that follows it, and returns the prefix as written. A keyword
+// not followed by SPACE or TAB (e.g. `export=1`) is left for the name scanner.
+// Options other than `declare -x` are rejected.
+func scanDeclaration(s *scanner) (string, error) {
+	startPos := s.pos
+	startLine := s.line
+	startCol := s.col
+	keyword := ""
+	for _, kw := range declarationKeywords {
+		rest := s.src[s.pos:]
+		if strings.HasPrefix(rest, kw) && len(rest) > len(kw) && (rest[len(kw)] == ' ' || rest[len(kw)] == '\t') {
+			keyword = kw
+			break
+		}
+	}
+	if keyword == "" {
+		return "", nil
+	}
+	s.advance(len(keyword))
+	skipBlanks(s)
+	var options []string
+	for r, _ := s.peek(); r == '-'; r, _ = s.peek() {
+		optPos := s.pos
+		for !s.eof() {
+			r, size := s.peek()
+			if r == ' ' || r == '\t' || r == '\n' {
+				break
+			}
+			s.advance(size)
+		}
+		options = append(options, s.src[optPos:s.pos])
+		skipBlanks(s)
+	}
+	valid := len(options) == 0
+	if keyword == "declare" {
+		valid = len(options) == 1 && options[0] == "-x"
+	}
+	if !valid {
+		prefix := strings.Join(append([]string{keyword}, options...), " ")
+		return "", newParseError(startLine, startCol, "EVE-103-104", fmt.Sprintf("unsupported declaration %q", prefix), prefix)
+	}
+	return s.src[startPos:s.pos], nil
+}
+
+// skipBlanks advances over SPACE and TAB.
+func skipBlanks(s *scanner) {
+	for r, size := s.peek(); r == ' ' || r == '\t'; r, size = s.peek() {
+		s.advance(size)
+	}
+}
+
 func scanAssignmentName(s *scanner) (string, ast.AssignmentOperator, error) {
 	startPos := s.pos
 	startLine := s.line
@@ -338,6 +406,9 @@ const (
 	frameSingle
 	frameBacktick
 	frameCommand
+	frameDollarSingle
+	frameParam
+	frameDoubleParam
 )
 
 type frame struct {
@@ -423,8 +494,29 @@ func scanValue(s *scanner) ([]ast.ValueToken, string, bool, error) {
 			return tokens, trailingComment, hasTrailingNewline, nil
 		}
 
-		if !escaped && ctx != ast.ContextSingleQuoted && ctx != ast.ContextBacktick && r == '$' {
+		if !escaped && r == '$' && (topKind(stack) == frameBare || topKind(stack) == frameCommand || topKind(stack) == frameParam) {
+			if nextRune, nextSize := s.peekAhead(size); nextRune == '\'' {
+				appendLiteral(r, ctx)
+				appendLiteral(nextRune, ctx)
+				s.advance(size + nextSize)
+				stack = append(stack, frame{kind: frameDollarSingle})
+				continue
+			}
+		}
+
+		if !escaped && ctx != ast.ContextSingleQuoted && ctx != ast.ContextDollarSingleQuoted && ctx != ast.ContextBacktick && r == '$' {
 			nextRune, nextSize := s.peekAhead(size)
+			if nextRune == '{' {
+				appendLiteral(r, ctx)
+				appendLiteral(nextRune, ctx)
+				s.advance(size + nextSize)
+				kind := frameParam
+				if ctx == ast.ContextDoubleQuoted || ctx == ast.ContextDoubleQuotedParameterExpansion {
+					kind = frameDoubleParam
+				}
+				stack = append(stack, frame{kind: kind})
+				continue
+			}
 			if nextRune == '(' {
 				appendLiteral(r, ctx)
 				s.advance(size)
@@ -438,20 +530,20 @@ func scanValue(s *scanner) ([]ast.ValueToken, string, bool, error) {
 		}
 
 		if !escaped {
-			if strings.HasPrefix(s.src[s.pos:], "<pass") {
+			if scheme, known := scanScheme(s.src, s.pos); known && isSigilViolation(s.src, s.pos, scheme) {
 				check := *s
-				check.advance(len("<pass"))
-				if !check.eof() {
-					if r2, _ := check.peek(); r2 == ' ' || r2 == '\t' || r2 == '\n' || r2 == '\r' {
-						return nil, "", false, newParseError(check.line, check.col, "EVE-103-4", "sigil violation: whitespace between 'pass' and ':'")
-					}
-				}
+				check.advance(len("<") + len(scheme))
+				return nil, "", false, newParseError(check.line, check.col, "EVE-103-4", fmt.Sprintf("sigil violation: whitespace between '%s' and ':'", scheme))
 			}
 			placeholderLine := s.line
 			placeholderCol := s.col
-			path, modifiers, length, ok, issue := scanPlaceholderLiteral(s.src, s.pos)
+			scheme, path, modifiers, length, ok, issue := scanPlaceholderLiteral(s.src, s.pos, s.col)
 			if issue != nil {
-				return nil, "", false, newParseError(s.line, s.col, issue.detailCode, issue.message, issue.detailArgs...)
+				col := s.col
+				if issue.column != 0 {
+					col = issue.column
+				}
+				return nil, "", false, newParseError(s.line, col, issue.detailCode, issue.message, issue.detailArgs...)
 			}
 			if ok {
 				flushLiteral()
@@ -459,6 +551,7 @@ func scanValue(s *scanner) ([]ast.ValueToken, string, bool, error) {
 				tokens = append(tokens, ast.ValueToken{
 					Kind:      ast.ValuePlaceholder,
 					Text:      raw,
+					Scheme:    scheme,
 					Path:      path,
 					Modifiers: modifiers,
 					Context:   ctx,
@@ -487,21 +580,25 @@ func scanValue(s *scanner) ([]ast.ValueToken, string, bool, error) {
 		case '"':
 			if topKind(stack) == frameDouble {
 				stack = stack[:len(stack)-1]
-			} else if topKind(stack) != frameSingle && topKind(stack) != frameBacktick {
+			} else if topKind(stack) != frameSingle && topKind(stack) != frameDollarSingle && topKind(stack) != frameBacktick {
 				stack = append(stack, frame{kind: frameDouble})
 			}
 		case '\'':
-			if topKind(stack) == frameSingle {
+			if topKind(stack) == frameSingle || topKind(stack) == frameDollarSingle {
 				stack = stack[:len(stack)-1]
-			} else if topKind(stack) != frameDouble && topKind(stack) != frameBacktick {
+			} else if topKind(stack) != frameDouble && topKind(stack) != frameDoubleParam && topKind(stack) != frameBacktick {
 				stack = append(stack, frame{kind: frameSingle})
 			}
 		case '`':
 			if topKind(stack) == frameBacktick {
 				stack = stack[:len(stack)-1]
-			} else if topKind(stack) != frameSingle {
+			} else if topKind(stack) != frameSingle && topKind(stack) != frameDollarSingle {
 				stack = append(stack, frame{kind: frameBacktick})
 			}
+		case '}':
+			if topKind(stack) == frameParam || topKind(stack) == frameDoubleParam {
+				stack = stack[:len(stack)-1]
+			}
 		case '(':
 			if topKind(stack) == frameCommand {
 				stack[len(stack)-1].parenDepth++
@@ -533,6 +630,12 @@ func scanValue(s *scanner) ([]ast.ValueToken, string, bool, error) {
 		case frameCommand:
 			msg = "unterminated command substitution"
 			code = "EVE-103-404"
+		case frameDollarSingle:
+			msg = "unterminated $'...' quote"
+			code = "EVE-103-405"
+		case frameParam, frameDoubleParam:
+			msg = "unterminated parameter expansion"
+			code = "EVE-103-406"
 		}
 		return nil, "", false, newParseError(s.line, s.col, code, msg)
 	}
@@ -548,13 +651,20 @@ func currentContext(stack []frame) ast.ValueContext {
 			return ast.ContextDoubleQuoted
 		case frameSingle:
 			return ast.ContextSingleQuoted
+		case frameDollarSingle:
+			return ast.ContextDollarSingleQuoted
+		case frameDoubleParam:
+			return ast.ContextDoubleQuotedParameterExpansion
 		case frameBacktick:
 			return ast.ContextBacktick
 		}
 	}
 	for i := len(stack) - 1; i >= 0; i-- {
-		if stack[i].kind == frameCommand {
+		switch stack[i].kind {
+		case frameCommand:
 			return ast.ContextCommandSubstitution
+		case frameParam:
+			return ast.ContextParameterExpansion
 		}
 	}
 	return ast.ContextBare
@@ -567,16 +677,93 @@ func topKind(stack []frame) frameKind {
 	return stack[len(stack)-1].kind
 }
 
+// validSchemes lists the placeholder schemes recognized after '<'. Each scheme
+// is served by a resolver registered by the caller; text such as `<http:` that
+// does not name a scheme here (or a plugin scheme `x-NAME`) remains literal.
+var validSchemes = map[string]struct{}{
+	"age":          {},
+	"bw":           {},
+	"env":          {},
+	"file":         {},
+	"op":           {},
+	"otp":          {},
+	"sops":         {},
+	"vault":        {},
+	ast.SchemePass: {},
+}
+
 var validModifiers = map[string]struct{}{
 	"dangerously_bypass_escape": {},
+	"first_line":                {},
+	"optional":                  {},
 	"allow_newline":             {},
 	"allow_tab":                 {},
 	"base64":                    {},
+	"base64_decode":             {},
+	"base64_nopad":              {},
+	"base64url":                 {},
+	"hex":                       {},
+	"urlencode":                 {},
 	"strip":                     {},
 	"strip_left":                {},
 	"strip_right":               {},
 }
 
+// parameterModifiers lists the modifiers written `name=value`, each with the
+// validation of its value.
+var parameterModifiers = map[string]func(string) bool{
+	"default":     func(string) bool { return true },
+	"field":       isFieldName,
+	"gen_charset": isGenCharset,
+	"gen_len":     isGenLength,
+	"line":        isPositiveDecimal,
+}
+
+// isGenCharset reports whether s names a character set for generated secrets.
+func isGenCharset(s string) bool {
+	_, ok := ast.GenCharsets[s]
+	return ok
+}
+
+// isGenLength reports whether s is a `gen_len=` length: a decimal from 1 to
+// ast.MaxGenLength.
+func isGenLength(s string) bool {
+	if !isPositiveDecimal(s) {
+		return false
+	}
+	n, _ := strconv.Atoi(s)
+	return n <= ast.MaxGenLength
+}
+
+// isFieldName reports whether s is a valid `field=` name: ASCII letters,
+// digits, '_', '-' and '.'.
+func isFieldName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '-' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// isPositiveDecimal reports whether s is a decimal integer >= 1 without
+// leading zeros.
+func isPositiveDecimal(s string) bool {
+	if s == "" || s[0] == '0' || len(s) > 9 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 func trimASCIIWhitespace(segment, subject, detailCode string) (string, *parseIssue) {
 	start := 0
 	end := len(segment)
@@ -610,26 +797,71 @@ func isNonASCIISpace(r rune) bool {
 	return unicode.IsSpace(r) && r != ' ' && r != '\t'
 }
 
-func scanPlaceholderLiteral(src string, start int) (string, []string, int, bool, *parseIssue) {
+// scanScheme reads the scheme name following '<' at start and reports whether
+// it is a recognized placeholder scheme.
+func scanScheme(src string, start int) (string, bool) {
+	if start >= len(src) || src[start] != '<' {
+		return "", false
+	}
+	i := start + 1
+	for i < len(src) && isSchemeByte(src[i]) {
+		i++
+	}
+	name := src[start+1 : i]
+	if _, ok := ast.PluginName(name); ok {
+		return name, true
+	}
+	_, ok := validSchemes[name]
+	return name, ok
+}
+
+// isSigilViolation reports whether the scheme name at start is followed by
+// whitespace that separates it from its ':'. Any whitespace after `<pass` is
+// a violation; for other schemes the whitespace must be followed by ':', so
+// literal text such as `<file name>` or `<op ...>` stays literal.
+func isSigilViolation(src string, start int, scheme string) bool {
+	i := start + len("<") + len(scheme)
+	if i >= len(src) || !isSigilSpace(src[i]) {
+		return false
+	}
+	if scheme == ast.SchemePass {
+		return true
+	}
+	for i < len(src) && isSigilSpace(src[i]) {
+		i++
+	}
+	return i < len(src) && src[i] == ':'
+}
+
+func isSigilSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isSchemeByte(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_' || c == '-'
+}
+
+// scanPlaceholderLiteral scans a placeholder starting with '<' at src[start],
+// which is at source column col.
+func scanPlaceholderLiteral(src string, start, col int) (string, string, []ast.Modifier, int, bool, *parseIssue) {
 	if start >= len(src) {
-		return "", nil, 0, false, nil
+		return "", "", nil, 0, false, nil
 	}
-	// Sigil detection: "<pass" followed by whitespace before ':' is a violation (EVE-103-4)
-	if strings.HasPrefix(src[start:], "<pass") && !strings.HasPrefix(src[start:], "<pass:") {
-		j := start + len("<pass")
-		if j < len(src) {
-			r := rune(src[j])
-			if unicode.IsSpace(r) {
-				return "", nil, 0, false, newParseIssue("EVE-103-4", "whitespace between 'pass' and ':' (sigil violation)")
-			}
-		}
+	scheme, known := scanScheme(src, start)
+	if !known {
+		return "", "", nil, 0, false, nil
 	}
-	if !strings.HasPrefix(src[start:], "<pass:") {
-		return "", nil, 0, false, nil
+	// Sigil detection: "<scheme" followed by whitespace before ':' is a violation (EVE-103-4)
+	if isSigilViolation(src, start, scheme) {
+		return "", "", nil, 0, false, newParseIssue("EVE-103-4", fmt.Sprintf("whitespace between '%s' and ':' (sigil violation)", scheme))
 	}
-	i := start + len("<pass:")
+	j := start + len("<") + len(scheme)
+	if j >= len(src) || src[j] != ':' {
+		return "", "", nil, 0, false, nil
+	}
+	i := j + 1
 	if i >= len(src) {
-		return "", nil, 0, false, newParseIssue("EVE-103-202", "unterminated placeholder")
+		return "", "", nil, 0, false, newParseIssue("EVE-103-202", "unterminated placeholder")
 	}
 	pathStart := i
 	for i < len(src) {
@@ -637,103 +869,154 @@ func scanPlaceholderLiteral(src string, start int) (string, []string, int, bool,
 		switch c {
 		case '>':
 			if i == pathStart {
-				return "", nil, 0, false, newParseIssue("EVE-103-201", "empty placeholder path")
+				return "", "", nil, 0, false, newParseIssue("EVE-103-201", "empty placeholder path")
 			}
 			segment := src[pathStart:i]
 			if strings.IndexByte(segment, 0) >= 0 {
-				return "", nil, 0, false, newParseIssue("EVE-103-203", "placeholder path contains NUL byte")
+				return "", "", nil, 0, false, newParseIssue("EVE-103-203", "placeholder path contains NUL byte")
 			}
 			path, issue := trimASCIIWhitespace(segment, "placeholder path", "EVE-103-204")
 			if issue != nil {
-				return "", nil, 0, false, issue
+				return "", "", nil, 0, false, issue
 			}
 			if path == "" {
-				return "", nil, 0, false, newParseIssue("EVE-103-201", "empty placeholder path")
+				return "", "", nil, 0, false, newParseIssue("EVE-103-201", "empty placeholder path")
 			}
 			if strings.IndexByte(path, 0) >= 0 {
-				return "", nil, 0, false, newParseIssue("EVE-103-203", "placeholder path contains NUL")
+				return "", "", nil, 0, false, newParseIssue("EVE-103-203", "placeholder path contains NUL")
 			}
-			return path, nil, i - start + 1, true, nil
+			return scheme, path, nil, i - start + 1, true, nil
 		case '|':
 			if i == pathStart {
-				return "", nil, 0, false, newParseIssue("EVE-103-201", "empty placeholder path")
+				return "", "", nil, 0, false, newParseIssue("EVE-103-201", "empty placeholder path")
 			}
 			segment := src[pathStart:i]
 			path, issue := trimASCIIWhitespace(segment, "placeholder path", "EVE-103-204")
 			if issue != nil {
-				return "", nil, 0, false, issue
+				return "", "", nil, 0, false, issue
 			}
 			if path == "" {
-				return "", nil, 0, false, newParseIssue("EVE-103-201", "empty placeholder path")
+				return "", "", nil, 0, false, newParseIssue("EVE-103-201", "empty placeholder path")
 			}
 			if strings.IndexByte(path, 0) >= 0 {
-				return "", nil, 0, false, newParseIssue("EVE-103-203", "placeholder path contains NUL")
+				return "", "", nil, 0, false, newParseIssue("EVE-103-203", "placeholder path contains NUL")
 			}
 			modStart := i + 1
-			j := modStart
-			for j < len(src) && src[j] != '>' && src[j] != '\n' {
-				j++
-			}
-			if j >= len(src) || src[j] != '>' {
-				return "", nil, 0, false, newParseIssue("EVE-103-202", "unterminated placeholder")
+			parts, j, issue := splitModifierSection(src, modStart)
+			if issue != nil {
+				return "", "", nil, 0, false, issue
 			}
 			// Separator-adjacent non-ASCII whitespace checks (EVE-103-1)
 			//  - immediately after '|'
 			if modStart < len(src) {
 				r, _ := utf8.DecodeRuneInString(src[modStart:])
 				if isNonASCIISpace(r) {
-					return "", nil, 0, false, newParseIssue("EVE-103-1", "non-ASCII whitespace around placeholder separators or before >")
+					return "", "", nil, 0, false, newParseIssue("EVE-103-1", "non-ASCII whitespace around placeholder separators or before >")
 				}
 			}
-			//  - around commas inside modifiers
-			{
-				k := modStart
-				for k < j {
-					r, size := utf8.DecodeRuneInString(src[k:])
-					if r == ',' {
-						// check previous
-						if k > modStart {
-							pr, _ := utf8.DecodeLastRuneInString(src[modStart:k])
-							if isNonASCIISpace(pr) {
-								return "", nil, 0, false, newParseIssue("EVE-103-1", "non-ASCII whitespace around placeholder separators or before >")
-							}
-						}
-						// check next
-						if k+size < j {
-							nr, _ := utf8.DecodeRuneInString(src[k+size:])
-							if isNonASCIISpace(nr) {
-								return "", nil, 0, false, newParseIssue("EVE-103-1", "non-ASCII whitespace around placeholder separators or before >")
-							}
-						}
-					}
-					k += size
+			//  - around commas between modifiers
+			for k := 0; k+1 < len(parts); k++ {
+				pr, _ := utf8.DecodeLastRuneInString(parts[k].text)
+				nr, _ := utf8.DecodeRuneInString(parts[k+1].text)
+				if isNonASCIISpace(pr) || isNonASCIISpace(nr) {
+					return "", "", nil, 0, false, newParseIssue("EVE-103-1", "non-ASCII whitespace around placeholder separators or before >")
 				}
 			}
 			//  - immediately before '>'
 			if j > modStart {
 				pr, _ := utf8.DecodeLastRuneInString(src[modStart:j])
 				if isNonASCIISpace(pr) {
-					return "", nil, 0, false, newParseIssue("EVE-103-1", "non-ASCII whitespace around placeholder separators or before >")
+					return "", "", nil, 0, false, newParseIssue("EVE-103-1", "non-ASCII whitespace around placeholder separators or before >")
 				}
 			}
-			modifiers, issue := parseModifiers(src[modStart:j])
+			modifiers, issue := parseModifiers(src[modStart:j], parts, func(offset int) int {
+				return col + utf8.RuneCountInString(src[start:offset])
+			})
 			if issue != nil {
-				return "", nil, 0, false, issue
+				return "", "", nil, 0, false, issue
 			}
 			if len(modifiers) == 0 {
-				return "", nil, 0, false, newParseIssue("EVE-103-301", "placeholder modifiers missing")
+				return "", "", nil, 0, false, newParseIssue("EVE-103-301", "placeholder modifiers missing")
 			}
-			return path, modifiers, j - start + 1, true, nil
+			if scheme != ast.SchemePass {
+				for _, m := range modifiers {
+					if isGenModifier(m.Name) {
+						issue := newParseIssue("EVE-103-310", fmt.Sprintf("placeholder modifier %q requires the pass scheme", m.Name), m.Name)
+						issue.column = m.Column
+						return "", "", nil, 0, false, issue
+					}
+				}
+			}
+			return scheme, path, modifiers, j - start + 1, true, nil
 		case '\n':
-			return "", nil, 0, false, newParseIssue("EVE-103-202", "unterminated placeholder")
+			return "", "", nil, 0, false, newParseIssue("EVE-103-202", "unterminated placeholder")
 		default:
 			i++
 		}
 	}
-	return "", nil, 0, false, newParseIssue("EVE-103-202", "unterminated placeholder")
+	return "", "", nil, 0, false, newParseIssue("EVE-103-202", "unterminated placeholder")
+}
+
+// modifierPart is one comma-separated segment of a modifier section, starting
+// at byte offset in the source.
+type modifierPart struct {
+	text   string
+	offset int
+}
+
+// splitModifierSection splits the modifier section that starts at src[start]
+// into its comma-separated parts and returns the index of the closing '>'. A
+// '"' directly after '=' opens a quoted argument, in which ',', '>' and '|'
+// are literal and '\' escapes the next character.
+func splitModifierSection(src string, start int) ([]modifierPart, int, *parseIssue) {
+	var parts []modifierPart
+	partStart := start
+	for i := start; i < len(src); i++ {
+		switch src[i] {
+		case '>':
+			parts = append(parts, modifierPart{text: src[partStart:i], offset: partStart})
+			return parts, i, nil
+		case '\n':
+			return nil, 0, newParseIssue("EVE-103-202", "unterminated placeholder")
+		case ',':
+			parts = append(parts, modifierPart{text: src[partStart:i], offset: partStart})
+			partStart = i + 1
+		case '"':
+			if i == start || src[i-1] != '=' {
+				continue
+			}
+			end, ok := skipQuotedArg(src, i)
+			if !ok {
+				name := strings.Trim(src[partStart:i-1], " \t")
+				return nil, 0, newParseIssue("EVE-103-307", fmt.Sprintf("unterminated quoted argument for placeholder modifier %q", name), name)
+			}
+			i = end
+		}
+	}
+	return nil, 0, newParseIssue("EVE-103-202", "unterminated placeholder")
+}
+
+// skipQuotedArg returns the index of the '"' closing the quoted argument
+// opened at src[open]. The argument ends at the line end at the latest.
+func skipQuotedArg(src string, open int) (int, bool) {
+	for i := open + 1; i < len(src); i++ {
+		switch src[i] {
+		case '"':
+			return i, true
+		case '\n':
+			return 0, false
+		case '\\':
+			if i+1 < len(src) && src[i+1] != '\n' {
+				i++
+			}
+		}
+	}
+	return 0, false
 }
 
-func parseModifiers(section string) ([]string, *parseIssue) {
+// parseModifiers validates the modifier section and converts its parts into
+// modifiers; column maps a source offset to its column.
+func parseModifiers(section string, parts []modifierPart, column func(offset int) int) ([]ast.Modifier, *parseIssue) {
 	trimmed, issue := trimASCIIWhitespace(section, "placeholder modifiers", "EVE-103-305")
 	if issue != nil {
 		return nil, issue
@@ -744,29 +1027,126 @@ func parseModifiers(section string) ([]string, *parseIssue) {
 	if trimmed == "" {
 		return nil, newParseIssue("EVE-103-301", "placeholder modifiers missing")
 	}
-	parts := strings.Split(trimmed, ",")
-	var modifiers []string
+	var modifiers []ast.Modifier
 	seen := make(map[string]struct{})
 	for _, part := range parts {
-		mod, issue := trimASCIIWhitespace(part, "placeholder modifiers", "EVE-103-305")
+		mod, issue := trimASCIIWhitespace(part.text, "placeholder modifiers", "EVE-103-305")
 		if issue != nil {
 			return nil, issue
 		}
 		if mod == "" {
 			return nil, newParseIssue("EVE-103-304", "invalid empty modifier")
 		}
-		if _, ok := validModifiers[mod]; !ok {
-			return nil, newParseIssue("EVE-103-302", fmt.Sprintf("unknown placeholder modifier %q", mod), mod)
+		lead := len(part.text) - len(strings.TrimLeft(part.text, " \t"))
+		name, argText, hasArg := strings.Cut(mod, "=")
+		m := ast.Modifier{Name: name, HasArg: hasArg, Column: column(part.offset + lead)}
+		valid, takesArg := parameterModifiers[name]
+		if !takesArg {
+			if _, ok := validModifiers[name]; !ok {
+				return nil, newParseIssue("EVE-103-302", fmt.Sprintf("unknown placeholder modifier %q", mod), mod)
+			}
+		}
+		if hasArg != takesArg {
+			return nil, newParseIssue("EVE-103-306", fmt.Sprintf("invalid argument for placeholder modifier %q", name), name)
+		}
+		if hasArg {
+			arg, issue := parseModifierArg(name, argText)
+			if issue != nil {
+				return nil, issue
+			}
+			if !valid(arg) {
+				return nil, newParseIssue("EVE-103-306", fmt.Sprintf("invalid argument for placeholder modifier %q", name), name)
+			}
+			m.Arg = arg
 		}
-		if _, exists := seen[mod]; exists {
-			return nil, newParseIssue("EVE-103-303", fmt.Sprintf("duplicate placeholder modifier %q", mod), mod)
+		if _, exists := seen[name]; exists {
+			return nil, newParseIssue("EVE-103-303", fmt.Sprintf("duplicate placeholder modifier %q", name), name)
 		}
-		modifiers = append(modifiers, mod)
-		seen[mod] = struct{}{}
+		modifiers = append(modifiers, m)
+		seen[name] = struct{}{}
+	}
+	if issue := checkModifierOrder(modifiers); issue != nil {
+		return nil, issue
 	}
 	return modifiers, nil
 }
 
+// isGenModifier reports whether the modifier named name configures secrets
+// generated by `sync --generate-missing`.
+func isGenModifier(name string) bool {
+	return name == "gen_len" || name == "gen_charset"
+}
+
+// encodingModifiers lists the modifiers that encode the value. Their output
+// is single-line ASCII without whitespace.
+var encodingModifiers = map[string]struct{}{
+	"base64":       {},
+	"base64_nopad": {},
+	"base64url":    {},
+	"hex":          {},
+	"urlencode":    {},
+}
+
+// checkModifierOrder validates the order of the value transforms, which apply
+// left to right. Once the value is encoded, only further encodings may follow:
+// a selector, strip or decode modifier after an encoding is reported at its
+// column.
+// Permission and fallback modifiers may appear anywhere.
+func checkModifierOrder(mods []ast.Modifier) *parseIssue {
+	encoded := ""
+	for _, m := range mods {
+		if _, ok := encodingModifiers[m.Name]; ok {
+			encoded = m.Name
+			continue
+		}
+		if encoded == "" {
+			continue
+		}
+		switch m.Name {
+		case "first_line", "line", "field", "strip", "strip_left", "strip_right", "base64_decode":
+			issue := newParseIssue("EVE-103-309", fmt.Sprintf("placeholder modifier %q cannot follow %q", m.Name, encoded), m.Name, encoded)
+			issue.column = m.Column
+			return issue
+		}
+	}
+	return nil
+}
+
+// parseModifierArg decodes the argument text after '=': either a bare argument
+// (see ast.IsBareModifierArg) or a double-quoted string in which `\"` and
+// `\\` are the only escapes. Control characters other than TAB are rejected.
+func parseModifierArg(name, text string) (string, *parseIssue) {
+	invalid := newParseIssue("EVE-103-306", fmt.Sprintf("invalid argument for placeholder modifier %q", name), name)
+	if !strings.HasPrefix(text, `"`) {
+		if !ast.IsBareModifierArg(text) {
+			return "", invalid
+		}
+		return text, nil
+	}
+	var b strings.Builder
+	for i := 1; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\\':
+			if i+1 < len(text) && (text[i+1] == '"' || text[i+1] == '\\') {
+				i++
+				b.WriteByte(text[i])
+				continue
+			}
+			return "", newParseIssue("EVE-103-308", fmt.Sprintf("invalid escape sequence in argument of placeholder modifier %q", name), name)
+		case c == '"':
+			if i != len(text)-1 {
+				return "", invalid
+			}
+			return b.String(), nil
+		case c < 0x20 && c != '\t', c == 0x7f:
+			return "", invalid
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", newParseIssue("EVE-103-307", fmt.Sprintf("unterminated quoted argument for placeholder modifier %q", name), name)
+}
+
 func (s *scanner) eof() bool {
 	return s.pos >= len(s.src)
 }
@@ -49,7 +49,7 @@ func TestParse_AssignmentWithPlaceholders(t *testing.T) {
 	if tokens[3].Kind != ast.ValuePlaceholder || tokens[3].Path != "key" {
 		t.Fatalf("unexpected fourth token: %#v", tokens[3])
 	}
-	if want := []string{"dangerously_bypass_escape"}; !testsupport.EqualStrings(tokens[3].Modifiers, want) {
+	if want := []string{"dangerously_bypass_escape"}; !testsupport.EqualStrings(testsupport.ModifierStrings(tokens[3].Modifiers), want) {
 		t.Fatalf("modifiers = %#v, want %#v", tokens[3].Modifiers, want)
 	}
 	if !assign.HasTrailingNewline {
@@ -66,7 +66,7 @@ func TestParse_ModifiersWithWhitespace(t *testing.T) {
 	}
 	mods := elems[0].Assignment.ValueTokens[0].Modifiers
 	want := []string{"allow_tab", "allow_newline"}
-	if !testsupport.EqualStrings(mods, want) {
+	if !testsupport.EqualStrings(testsupport.ModifierStrings(mods), want) {
 		t.Fatalf("modifiers = %#v, want %#v", mods, want)
 	}
 }
@@ -135,6 +135,68 @@ func TestParse_SingleQuotedLiteralPlaceholder(t *testing.T) {
 	}
 }
 
+// [EVT-MGU-1]
+func TestParse_DollarSingleQuotedPlaceholderContext(t *testing.T) {
+	input := "RAW=$'it\\'s <pass:secret>' # note\n"
+	elems, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	assign := elems[0].Assignment
+	placeholder := findFirstPlaceholder(assign.ValueTokens)
+	if placeholder == nil {
+		t.Fatalf("expected placeholder token")
+	}
+	if placeholder.Context != ast.ContextDollarSingleQuoted {
+		t.Fatalf("placeholder context = %v, want dollar single-quoted", placeholder.Context)
+	}
+	if assign.TrailingComment != "# note" {
+		t.Fatalf("trailing comment = %q, want %q", assign.TrailingComment, "# note")
+	}
+}
+
+// [EVT-MGU-1]
+func TestParse_UnterminatedDollarSingleQuote(t *testing.T) {
+	input := "BROKEN=$'it\\'s\n"
+	_, err := parser.Parse(input)
+	expectParseError(t, err, "EVE-103-405")
+}
+
+// [EVT-MGU-1]
+func TestParse_ParameterExpansionPlaceholderContext(t *testing.T) {
+	cases := []struct {
+		input string
+		want  ast.ValueContext
+	}{
+		{"DB_HOST=${DB_HOST:-<pass:db/host>}\n", ast.ContextParameterExpansion},
+		{"DB_HOST=\"${DB_HOST:-<pass:db/host>}\"\n", ast.ContextDoubleQuotedParameterExpansion},
+		{"DB_HOST=${DB_HOST:-'<pass:db/host>'}\n", ast.ContextSingleQuoted},
+		{"DB_HOST=\"${DB_HOST:-'<pass:db/host>'}\"\n", ast.ContextDoubleQuotedParameterExpansion},
+		{"DB_HOST=${DB_HOST:-$(echo <pass:db/host>)}\n", ast.ContextCommandSubstitution},
+	}
+	for _, tc := range cases {
+		elems, err := parser.Parse(tc.input)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tc.input, err)
+		}
+		placeholder := findFirstPlaceholder(elems[0].Assignment.ValueTokens)
+		if placeholder == nil {
+			t.Fatalf("Parse(%q): expected placeholder token", tc.input)
+		}
+		if placeholder.Context != tc.want {
+			t.Fatalf("Parse(%q): placeholder context = %v, want %v", tc.input, placeholder.Context, tc.want)
+		}
+	}
+}
+
+// [EVT-MGU-1]
+func TestParse_UnterminatedParameterExpansion(t *testing.T) {
+	for _, input := range []string{"BROKEN=${HOST:-localhost\n", "BROKEN=${HOST:-a\\}\n"} {
+		_, err := parser.Parse(input)
+		expectParseError(t, err, "EVE-103-406")
+	}
+}
+
 // [EVT-MGU-1][EVT-MGU-4]
 func TestParse_TrailingCommentPreserved(t *testing.T) {
 	input := "KEY=value # trailing\n"
@@ -236,7 +298,7 @@ func TestParse_AllowsModifierCombination(t *testing.T) {
 				t.Fatalf("Parse error: %v", err)
 			}
 			mods := elems[0].Assignment.ValueTokens[0].Modifiers
-			if !testsupport.EqualStrings(mods, tc.want) {
+			if !testsupport.EqualStrings(testsupport.ModifierStrings(mods), tc.want) {
 				t.Fatalf("modifiers = %#v, want %#v", mods, tc.want)
 			}
 		})
@@ -268,7 +330,7 @@ func TestParse_AllowModifiersSpacing(t *testing.T) {
 	}
 	mods := elems[0].Assignment.ValueTokens[0].Modifiers
 	want := []string{"allow_tab", "allow_newline"}
-	if !testsupport.EqualStrings(mods, want) {
+	if !testsupport.EqualStrings(testsupport.ModifierStrings(mods), want) {
 		t.Fatalf("modifiers = %#v, want %#v", mods, want)
 	}
 }
@@ -460,6 +522,268 @@ func TestParse_SigilViolationWhitespace(t *testing.T) {
 	}
 }
 
+// [EVT-MPU-8]
+func TestParse_PlaceholderSchemes(t *testing.T) {
+	input := "A=<env:HOME>\nB=<file:certs/ca.pem|strip>\nC=<pass:secret>\nD=<age:secrets/token.age>\nE=<sops:secrets/prod.yaml#db.password>\nF=<vault:secret/data/app#password>\nG=<op:Private/GitHub/token>\nH=<bw:github#username>\nI=<x-keychain:work/api-token>\nJ=<otp:web/example.com>\n"
+	elems, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want := []struct{ scheme, path string }{
+		{"env", "HOME"},
+		{"file", "certs/ca.pem"},
+		{ast.SchemePass, "secret"},
+		{"age", "secrets/token.age"},
+		{"sops", "secrets/prod.yaml#db.password"},
+		{"vault", "secret/data/app#password"},
+		{"op", "Private/GitHub/token"},
+		{"bw", "github#username"},
+		{"x-keychain", "work/api-token"},
+		{"otp", "web/example.com"},
+	}
+	for i, w := range want {
+		tok := findFirstPlaceholder(elems[i].Assignment.ValueTokens)
+		if tok == nil {
+			t.Fatalf("line %d: placeholder not found", i+1)
+		}
+		if tok.Scheme != w.scheme || tok.Path != w.path {
+			t.Fatalf("line %d: scheme/path = %q/%q, want %q/%q", i+1, tok.Scheme, tok.Path, w.scheme, w.path)
+		}
+	}
+}
+
+// [EVT-MPU-8]
+func TestParse_UnknownSchemeRemainsLiteral(t *testing.T) {
+	for _, literal := range []string{"<https://example.com>", "<x-:path>"} {
+		elems, err := parser.Parse("URL=" + literal + "\n")
+		if err != nil {
+			t.Fatalf("Parse error: %v", err)
+		}
+		if tok := findFirstPlaceholder(elems[0].Assignment.ValueTokens); tok != nil {
+			t.Fatalf("unexpected placeholder %+v", *tok)
+		}
+		if got := joinTokenText(elems[0].Assignment.ValueTokens); got != literal {
+			t.Fatalf("literal text = %q, want %q", got, literal)
+		}
+	}
+}
+
+// [EVT-MPU-9]
+func TestParse_SelectorModifiers(t *testing.T) {
+	elems, err := parser.Parse("A=<pass:site|first_line>\nB=<pass:site | field=user_name , strip>\nC=<pass:site|line=12>\n")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want := [][]string{{"first_line"}, {"field=user_name", "strip"}, {"line=12"}}
+	for i, w := range want {
+		tok := findFirstPlaceholder(elems[i].Assignment.ValueTokens)
+		if tok == nil || strings.Join(testsupport.ModifierStrings(tok.Modifiers), ",") != strings.Join(w, ",") {
+			t.Fatalf("line %d: modifiers = %v, want %v", i+1, tok, w)
+		}
+	}
+	for in, code := range map[string]string{
+		"V=<pass:site|line=0>\n":                "EVE-103-306",
+		"V=<pass:site|line=01>\n":               "EVE-103-306",
+		"V=<pass:site|line=x>\n":                "EVE-103-306",
+		"V=<pass:site|line>\n":                  "EVE-103-306",
+		"V=<pass:site|field=>\n":                "EVE-103-306",
+		"V=<pass:site|field=a b>\n":             "EVE-103-306",
+		"V=<pass:site|strip=1>\n":               "EVE-103-306",
+		"V=<pass:site|fields=user>\n":           "EVE-103-302",
+		"V=<pass:site|field=a,field=b>\n":       "EVE-103-303",
+		"V=<pass:site|first_line,first_line>\n": "EVE-103-303",
+	} {
+		_, err := parser.Parse(in)
+		expectParseError(t, err, code)
+	}
+}
+
+// [EVT-MPU-10]
+func TestParse_ModifierArguments(t *testing.T) {
+	input := "A=<pass:site|field=\"user\">\nB=\"<pass:site | strip, field=\"a.b\" >\"\nC=<pass:site|line=\"2\",strip>\n"
+	elems, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want := [][]ast.Modifier{
+		{{Name: "field", Arg: "user", HasArg: true, Column: 14}},
+		{{Name: "strip", Column: 17}, {Name: "field", Arg: "a.b", HasArg: true, Column: 24}},
+		{{Name: "line", Arg: "2", HasArg: true, Column: 14}, {Name: "strip", Column: 23}},
+	}
+	for i, w := range want {
+		tok := findFirstPlaceholder(elems[i].Assignment.ValueTokens)
+		if tok == nil || len(tok.Modifiers) != len(w) {
+			t.Fatalf("line %d: token = %+v, want modifiers %+v", i+1, tok, w)
+		}
+		for k := range w {
+			if tok.Modifiers[k] != w[k] {
+				t.Fatalf("line %d: modifier %d = %#v, want %#v", i+1, k, tok.Modifiers[k], w[k])
+			}
+		}
+	}
+
+	for in, code := range map[string]string{
+		"V=<pass:site|field=\"user>\n":           "EVE-103-307",
+		"V=<pass:site|field=\"us>er\n":           "EVE-103-307",
+		"V=<pass:site|field=\"user\\\">\n":       "EVE-103-307",
+		"V=<pass:site|field=\"us\\er\">\n":       "EVE-103-308",
+		"V=<pass:site|field=\"user\"x>\n":        "EVE-103-306",
+		"V=<pass:site|field=\"a,b\">\n":          "EVE-103-306",
+		"V=<pass:site|field=\"\">\n":             "EVE-103-306",
+		"V=<pass:site|field = user>\n":           "EVE-103-302",
+		"V=<pass:site|field= user>\n":            "EVE-103-306",
+		"V=<pass:site|field=us'er>\n":            "EVE-103-306",
+		"V=<pass:site|strip=\"\">\n":             "EVE-103-306",
+		"V=<pass:site|st\"rip>\n":                "EVE-103-302",
+		"V=<pass:site|field=\"user\",field=x>\n": "EVE-103-303",
+	} {
+		_, err := parser.Parse(in)
+		expectParseError(t, err, code)
+	}
+}
+
+// [EVT-MPU-10]
+func TestParse_ModifierStringRoundTrip(t *testing.T) {
+	mods := []ast.Modifier{
+		{Name: "strip"},
+		{Name: "field", Arg: "user", HasArg: true},
+		{Name: "field", Arg: "a b, <c>|d", HasArg: true},
+		{Name: "field", Arg: `say "hi" \ bye`, HasArg: true},
+		{Name: "field", Arg: "", HasArg: true},
+	}
+	want := []string{"strip", "field=user", `field="a b, <c>|d"`, `field="say \"hi\" \\ bye"`, `field=""`}
+	for i, m := range mods {
+		if got := m.String(); got != want[i] {
+			t.Fatalf("String(%+v) = %q, want %q", m, got, want[i])
+		}
+	}
+	// Arguments that field= accepts survive a parse of their template syntax.
+	m := ast.Modifier{Name: "field", Arg: "x.y-z", HasArg: true}
+	elems, err := parser.Parse("V=<pass:site|" + m.String() + ">\n")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if got := elems[0].Assignment.ValueTokens[0].Modifiers[0]; got.Name != m.Name || got.Arg != m.Arg || !got.HasArg {
+		t.Fatalf("round trip = %+v, want %+v", got, m)
+	}
+}
+
+// [EVT-MPU-11]
+func TestParse_OptionalModifiers(t *testing.T) {
+	elems, err := parser.Parse("A=<pass:dsn|optional>\nB=<pass:key|default=\"\">\nC=<pass:key|strip,default=\"a, b > c\">\n")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want := []ast.Modifier{
+		{Name: "optional"},
+		{Name: "default", HasArg: true},
+		{Name: "default", Arg: "a, b > c", HasArg: true},
+	}
+	for i, w := range want {
+		mods := elems[i].Assignment.ValueTokens[0].Modifiers
+		got := mods[len(mods)-1]
+		if got.Name != w.Name || got.Arg != w.Arg || got.HasArg != w.HasArg {
+			t.Fatalf("line %d: modifier = %#v, want %#v", i+1, got, w)
+		}
+	}
+	for in, code := range map[string]string{
+		"V=<pass:key|default>\n":             "EVE-103-306",
+		"V=<pass:key|default=>\n":            "EVE-103-306",
+		"V=<pass:key|optional=yes>\n":        "EVE-103-306",
+		"V=<pass:key|default=a,default=b>\n": "EVE-103-303",
+		"V=<pass:key|optional,optional>\n":   "EVE-103-303",
+	} {
+		_, err := parser.Parse(in)
+		expectParseError(t, err, code)
+	}
+}
+
+// [EVT-MPU-13]
+func TestParse_ModifierOrder(t *testing.T) {
+	elems, err := parser.Parse("V=<pass:key|strip,base64,urlencode,allow_tab>\n")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	got := testsupport.ModifierStrings(elems[0].Assignment.ValueTokens[0].Modifiers)
+	if want := []string{"strip", "base64", "urlencode", "allow_tab"}; !testsupport.EqualStrings(got, want) {
+		t.Fatalf("modifiers = %v, want %v", got, want)
+	}
+	cases := []struct {
+		input  string
+		column int
+	}{
+		{"V=<pass:key|base64,strip>\n", 20},
+		{"V=<pass:key|hex, strip_left>\n", 18},
+		{"V=<pass:key|optional,urlencode,field=user>\n", 32},
+		{"V=<pass:key|base64,allow_tab,default=x,first_line>\n", 40},
+		{"V=<pass:key|hex,base64_decode>\n", 17},
+	}
+	for _, tc := range cases {
+		_, err := parser.Parse(tc.input)
+		perr := expectParseError(t, err, "EVE-103-309")
+		if perr.Line != 1 || perr.Column != tc.column {
+			t.Fatalf("%q: error position = (%d, %d), want (1, %d)", tc.input, perr.Line, perr.Column, tc.column)
+		}
+	}
+}
+
+// [EVT-MPU-15]
+func TestParse_GenModifiers(t *testing.T) {
+	elems, err := parser.Parse("KEY=<pass:jwt/key|gen_len=48,gen_charset=hex,base64>\n")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	got := testsupport.ModifierStrings(elems[0].Assignment.ValueTokens[0].Modifiers)
+	if want := []string{"gen_len=48", "gen_charset=hex", "base64"}; !testsupport.EqualStrings(got, want) {
+		t.Fatalf("modifiers = %v, want %v", got, want)
+	}
+	for _, in := range []string{
+		"V=<pass:k|gen_len=0>\n",
+		"V=<pass:k|gen_len=4097>\n",
+		"V=<pass:k|gen_len=x>\n",
+		"V=<pass:k|gen_len>\n",
+		"V=<pass:k|gen_charset=emoji>\n",
+	} {
+		_, err := parser.Parse(in)
+		expectParseError(t, err, "EVE-103-306")
+	}
+	_, err = parser.Parse("V=<env:HOME|strip,gen_len=8>\n")
+	perr := expectParseError(t, err, "EVE-103-310")
+	if perr.Line != 1 || perr.Column != 19 {
+		t.Fatalf("error position = (%d, %d), want (1, 19)", perr.Line, perr.Column)
+	}
+}
+
+// [EVT-MPU-2][EVT-MPU-8]
+func TestParse_SigilViolationWhitespaceAnyScheme(t *testing.T) {
+	for _, in := range []string{"VAR=<env :HOME>\n", "VAR=<file\t:path>\n"} {
+		_, err := parser.Parse(in)
+		expectParseError(t, err, "EVE-103-4")
+	}
+}
+
+// [EVT-MPU-2][EVT-MPU-8]
+func TestParse_SchemeNameFollowedByTextStaysLiteral(t *testing.T) {
+	input := "A=\"<file name>\"\nB='<op ...>'\nC=\"use <env var> here\"\n"
+	elems, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	for i, want := range []string{"<file name>", "<op ...>", "use <env var> here"} {
+		tokens := elems[i].Assignment.ValueTokens
+		if p := findFirstPlaceholder(tokens); p != nil {
+			t.Fatalf("line %d: unexpected placeholder %q", i+1, p.Text)
+		}
+		got, err := renderElementsText(elems[i : i+1])
+		if err != nil {
+			t.Fatalf("render error: %v", err)
+		}
+		if !strings.Contains(got, want) {
+			t.Fatalf("line %d = %q, want literal %q", i+1, got, want)
+		}
+	}
+}
+
 // [EVT-MPU-5]
 func TestParse_Base64Modifier(t *testing.T) {
 	input := "TOKEN=<pass:secret|base64>\n"
@@ -469,7 +793,7 @@ func TestParse_Base64Modifier(t *testing.T) {
 	}
 	mods := elems[0].Assignment.ValueTokens[0].Modifiers
 	want := []string{"base64"}
-	if !testsupport.EqualStrings(mods, want) {
+	if !testsupport.EqualStrings(testsupport.ModifierStrings(mods), want) {
 		t.Fatalf("modifiers = %#v, want %#v", mods, want)
 	}
 }
@@ -499,7 +823,7 @@ func TestParse_Base64CombinationAllowed(t *testing.T) {
 				t.Fatalf("Parse error: %v", err)
 			}
 			mods := elems[0].Assignment.ValueTokens[0].Modifiers
-			if !testsupport.EqualStrings(mods, tc.want) {
+			if !testsupport.EqualStrings(testsupport.ModifierStrings(mods), tc.want) {
 				t.Fatalf("modifiers = %#v, want %#v", mods, tc.want)
 			}
 		})
@@ -567,6 +891,55 @@ func TestParse_AssignmentNameMismatchedBrackets(t *testing.T) {
 	expectParseError(t, err, "EVE-103-501")
 }
 
+// [EVT-MGU-7]
+func TestParse_DeclarationPrefixes(t *testing.T) {
+	input := strings.Join([]string{
+		`export API_KEY=<pass:api>`,
+		`  readonly	MODE="prod"`,
+		`declare  -x PATH+=":<pass:bin>"`,
+		`export=literal`,
+	}, "\n") + "\n"
+	elems, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want := []struct {
+		declaration, name string
+		op                ast.AssignmentOperator
+	}{
+		{"export ", "API_KEY", ast.OperatorAssign},
+		{"readonly\t", "MODE", ast.OperatorAssign},
+		{"declare  -x ", "PATH", ast.OperatorAppend},
+		{"", "export", ast.OperatorAssign},
+	}
+	for i, w := range want {
+		as := elems[i].Assignment
+		if as.Declaration != w.declaration || as.Name != w.name || as.Operator != w.op {
+			t.Fatalf("line %d: declaration %q, name %q, op %v; want %q, %q, %v", i+1, as.Declaration, as.Name, as.Operator, w.declaration, w.name, w.op)
+		}
+	}
+	if tok := findFirstPlaceholder(elems[0].Assignment.ValueTokens); tok == nil || tok.Context != ast.ContextBare || tok.Column != 16 {
+		t.Fatalf("API_KEY placeholder = %#v, want bare context at column 16", tok)
+	}
+	if tok := findFirstPlaceholder(elems[2].Assignment.ValueTokens); tok == nil || tok.Context != ast.ContextDoubleQuoted {
+		t.Fatalf("PATH placeholder = %#v, want double-quoted context", tok)
+	}
+
+	for _, tc := range []struct {
+		input, code string
+	}{
+		{"declare FOO=1\n", "EVE-103-104"},
+		{"declare -a LIST=1\n", "EVE-103-104"},
+		{"declare -x -r FOO=1\n", "EVE-103-104"},
+		{"export -n FOO=1\n", "EVE-103-104"},
+		{"export FOO\n", "EVE-103-102"},
+		{"export 1FOO=1\n", "EVE-103-101"},
+	} {
+		_, err := parser.Parse(tc.input)
+		expectParseError(t, err, tc.code)
+	}
+}
+
 func findFirstPlaceholder(tokens []ast.ValueToken) *ast.ValueToken {
 	for i := range tokens {
 		if tokens[i].Kind == ast.ValuePlaceholder {
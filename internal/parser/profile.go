@@ -0,0 +1,235 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"envseed/internal/ast"
+)
+
+// VarsDirective starts a whole-line comment that declares a profile and its
+// template variables: `#@vars prod prefix=myapp/prod region=eu`.
+const VarsDirective = "#@vars"
+
+// ProfileVar is the template variable that holds the selected profile name.
+const ProfileVar = "profile"
+
+// varsDirective is a parsed `#@vars PROFILE NAME=VALUE...` directive.
+type varsDirective struct {
+	profile string
+	names   []string
+	values  []string
+}
+
+// IsProfileName reports whether name can select a profile: ASCII letters,
+// digits, '_', '-' and '.', not starting with '.'.
+func IsProfileName(name string) bool {
+	if name == "" || name[0] == '.' {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '_', c == '-', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Profiles returns the profiles declared by `#@vars` directives, in the order
+// they first appear.
+func Profiles(elems []ast.Element) ([]string, error) {
+	var profiles []string
+	seen := map[string]bool{}
+	for _, el := range elems {
+		dir, ok, err := parseVarsDirective(el)
+		if err != nil {
+			return nil, err
+		}
+		if ok && !seen[dir.profile] {
+			seen[dir.profile] = true
+			profiles = append(profiles, dir.profile)
+		}
+	}
+	return profiles, nil
+}
+
+// ExpandProfile replaces each `{{NAME}}` in placeholder paths with the value
+// of NAME in profile and drops the `#@vars` directives. NAME is ProfileVar or
+// a variable that a `#@vars` directive defines for profile; a later definition
+// overrides an earlier one. With an empty profile, a template that references
+// any variable is rejected. elems is not modified.
+func ExpandProfile(elems []ast.Element, profile string) ([]ast.Element, error) {
+	vars := map[string]string{}
+	if profile != "" {
+		vars[ProfileVar] = profile
+	}
+	out := make([]ast.Element, 0, len(elems))
+	for _, el := range elems {
+		dir, ok, err := parseVarsDirective(el)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			out = append(out, el)
+			continue
+		}
+		if dir.profile == profile {
+			for i, name := range dir.names {
+				vars[name] = dir.values[i]
+			}
+		}
+	}
+	for i, el := range out {
+		if el.Type != ast.ElementAssignment || el.Assignment == nil {
+			continue
+		}
+		var tokens []ast.ValueToken
+		for j, tok := range el.Assignment.ValueTokens {
+			if tok.Kind != ast.ValuePlaceholder || !strings.Contains(tok.Path, "{{") {
+				continue
+			}
+			path, err := expandPath(tok, profile, vars)
+			if err != nil {
+				err.File = el.Assignment.File
+				return nil, err
+			}
+			if tokens == nil {
+				tokens = append([]ast.ValueToken(nil), el.Assignment.ValueTokens...)
+			}
+			tokens[j].Path = path
+		}
+		if tokens != nil {
+			assignment := *el.Assignment
+			assignment.ValueTokens = tokens
+			out[i].Assignment = &assignment
+		}
+	}
+	return out, nil
+}
+
+// expandPath substitutes the variable references in the path of tok.
+func expandPath(tok ast.ValueToken, profile string, vars map[string]string) (string, *ParseError) {
+	var b strings.Builder
+	rest := tok.Path
+	for {
+		open := strings.Index(rest, "{{")
+		if open < 0 {
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		b.WriteString(rest[:open])
+		end := strings.Index(rest[open+2:], "}}")
+		if end < 0 || !isVarName(rest[open+2:open+2+end]) {
+			ref := rest[open:]
+			if end >= 0 {
+				ref = rest[open : open+2+end+2]
+			}
+			return "", newParseError(tok.Line, tok.Column, "EVE-103-12", fmt.Sprintf("malformed template variable reference %q", ref), ref)
+		}
+		name := rest[open+2 : open+2+end]
+		if profile == "" {
+			return "", newParseError(tok.Line, tok.Column, "EVE-103-11", fmt.Sprintf("template variable %q requires a profile", name))
+		}
+		value, ok := vars[name]
+		if !ok {
+			return "", newParseError(tok.Line, tok.Column, "EVE-103-10", fmt.Sprintf("undefined template variable %q in profile %q", name, profile), name, profile)
+		}
+		b.WriteString(value)
+		rest = rest[open+2+end+2:]
+	}
+}
+
+// parseVarsDirective parses el when it is a `#@vars` directive.
+func parseVarsDirective(el ast.Element) (varsDirective, bool, *ParseError) {
+	if el.Type != ast.ElementComment {
+		return varsDirective{}, false, nil
+	}
+	text := strings.TrimRight(el.Text, "\r")
+	body := strings.TrimLeft(text, " \t")
+	rest, ok := strings.CutPrefix(body, VarsDirective)
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return varsDirective{}, false, nil
+	}
+	offset := len(text) - len(rest)
+	fail := func(at int, message string) *ParseError {
+		column := utf8.RuneCountInString(text[:at]) + 1
+		return newParseError(el.Line, column, "EVE-103-9", message)
+	}
+
+	var dir varsDirective
+	for _, field := range splitFields(rest) {
+		at := offset + field.offset
+		if dir.profile == "" {
+			if !IsProfileName(field.text) {
+				return varsDirective{}, false, fail(at, fmt.Sprintf("invalid profile name %q in %s directive", field.text, VarsDirective))
+			}
+			dir.profile = field.text
+			continue
+		}
+		name, value, found := strings.Cut(field.text, "=")
+		if !found || !isVarName(name) {
+			return varsDirective{}, false, fail(at, fmt.Sprintf("expected NAME=VALUE in %s directive, got %q", VarsDirective, field.text))
+		}
+		if name == ProfileVar {
+			return varsDirective{}, false, fail(at, fmt.Sprintf("template variable %q is reserved", ProfileVar))
+		}
+		if i := strings.IndexFunc(value, isForbiddenVarRune); i >= 0 {
+			return varsDirective{}, false, fail(at+len(name)+1+i, fmt.Sprintf("template variable %q contains %q", name, value[i:i+1]))
+		}
+		dir.names = append(dir.names, name)
+		dir.values = append(dir.values, value)
+	}
+	if dir.profile == "" {
+		return varsDirective{}, false, fail(len(text), "missing profile name in "+VarsDirective+" directive")
+	}
+	return dir, true, nil
+}
+
+// varsField is a whitespace-separated field of a directive, starting at byte
+// offset in the text after the directive name.
+type varsField struct {
+	text   string
+	offset int
+}
+
+func splitFields(s string) []varsField {
+	var fields []varsField
+	start := -1
+	for i := 0; i <= len(s); i++ {
+		if i == len(s) || s[i] == ' ' || s[i] == '\t' {
+			if start >= 0 {
+				fields = append(fields, varsField{text: s[start:i], offset: start})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	return fields
+}
+
+func isVarName(name string) bool {
+	for i, r := range name {
+		if i == 0 && !isNameStart(r) || !isNameChar(r) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// isForbiddenVarRune reports runes that cannot appear in a placeholder path or
+// would end the placeholder early.
+func isForbiddenVarRune(r rune) bool {
+	switch r {
+	case '<', '>', '|', '{', '}':
+		return true
+	}
+	return r < 0x20 || r == 0x7f
+}
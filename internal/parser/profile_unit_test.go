@@ -0,0 +1,112 @@
+package parser_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"envseed/internal/ast"
+	"envseed/internal/parser"
+)
+
+const profileTemplate = "#@vars dev prefix=myapp/dev\n" +
+	"#@vars prod prefix=myapp/prod\n" +
+	"#@vars prod region=eu\n" +
+	"DB_PASSWORD=<pass:{{prefix}}/db/password>\n" +
+	"REGION='<pass:shared/{{profile}}-{{region}}|allow_newline>'\n" +
+	"# {{prefix}} stays literal outside placeholder paths\n"
+
+// [EVT-MGU-9]
+func TestExpandProfile_SubstitutesProfileVariables(t *testing.T) {
+	elems, err := parser.Parse(profileTemplate)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	profiles, err := parser.Profiles(elems)
+	if err != nil {
+		t.Fatalf("Profiles error: %v", err)
+	}
+	if want := []string{"dev", "prod"}; !reflect.DeepEqual(profiles, want) {
+		t.Fatalf("Profiles = %v, want %v", profiles, want)
+	}
+
+	expanded, err := parser.ExpandProfile(elems, "prod")
+	if err != nil {
+		t.Fatalf("ExpandProfile error: %v", err)
+	}
+	var paths []string
+	for _, el := range expanded {
+		if el.Type == ElementComment && strings.HasPrefix(el.Text, parser.VarsDirective) {
+			t.Fatalf("directive %q kept in expanded template", el.Text)
+		}
+		if el.Type != ElementAssignment {
+			continue
+		}
+		for _, tok := range el.Assignment.ValueTokens {
+			if tok.Kind == ast.ValuePlaceholder {
+				paths = append(paths, tok.Path)
+			}
+		}
+	}
+	if want := []string{"myapp/prod/db/password", "shared/prod-eu"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("expanded paths = %v, want %v", paths, want)
+	}
+	if got := findFirstPlaceholder(elems[3].Assignment.ValueTokens).Path; got != "{{prefix}}/db/password" {
+		t.Fatalf("ExpandProfile modified its input: %q", got)
+	}
+}
+
+// [EVT-MGU-9]
+func TestExpandProfile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		profile string
+		code    string
+		column  int
+	}{
+		{name: "undefined variable", input: "#@vars dev prefix=a\n#@vars prod\nV=<pass:{{prefix}}/x>\n", profile: "prod", code: "EVE-103-10", column: 3},
+		{name: "no profile selected", input: "V=<pass:{{profile}}/x>\n", code: "EVE-103-11", column: 3},
+		{name: "unterminated reference", input: "V=<pass:{{profile/x>\n", profile: "dev", code: "EVE-103-12", column: 3},
+		{name: "invalid reference name", input: "V=<pass:{{ profile }}/x>\n", profile: "dev", code: "EVE-103-12", column: 3},
+		{name: "missing profile", input: "#@vars\n", code: "EVE-103-9", column: 7},
+		{name: "invalid profile", input: "#@vars ../prod\n", code: "EVE-103-9", column: 8},
+		{name: "not NAME=VALUE", input: "#@vars dev prefix\n", code: "EVE-103-9", column: 12},
+		{name: "reserved name", input: "#@vars dev profile=x\n", code: "EVE-103-9", column: 12},
+		{name: "forbidden value", input: "#@vars dev prefix=a>b\n", code: "EVE-103-9", column: 20},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			elems, err := parser.Parse(tc.input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			_, err = parser.ExpandProfile(elems, tc.profile)
+			var perr *parser.ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected ParseError, got %v", err)
+			}
+			if perr.DetailCode != tc.code || perr.Column != tc.column {
+				t.Fatalf("error = %s at column %d, want %s at column %d (%v)", perr.DetailCode, perr.Column, tc.code, tc.column, perr)
+			}
+		})
+	}
+}
+
+// [EVT-MGU-9]
+func TestParseFile_ReportsVarsDirectiveFile(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		".envseed":        "#@include .envseed.common\n",
+		".envseed.common": "A=1\n#@vars prod bad\n",
+	})
+	_, err := parseTemplateFile(t, dir, ".envseed")
+	var perr *parser.ParseError
+	if !errors.As(err, &perr) || perr.DetailCode != "EVE-103-9" {
+		t.Fatalf("expected EVE-103-9, got %v", err)
+	}
+	if want := filepath.Join(dir, ".envseed.common") + ":2:13:"; !strings.HasPrefix(perr.Error(), want) {
+		t.Fatalf("error = %q, want prefix %q", perr.Error(), want)
+	}
+}
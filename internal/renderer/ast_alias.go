@@ -19,11 +19,14 @@ const (
 	ElementComment    = ast.ElementComment
 	ElementBlank      = ast.ElementBlank
 
-	ContextBare                = ast.ContextBare
-	ContextDoubleQuoted        = ast.ContextDoubleQuoted
-	ContextSingleQuoted        = ast.ContextSingleQuoted
-	ContextCommandSubstitution = ast.ContextCommandSubstitution
-	ContextBacktick            = ast.ContextBacktick
+	ContextBare                           = ast.ContextBare
+	ContextDoubleQuoted                   = ast.ContextDoubleQuoted
+	ContextSingleQuoted                   = ast.ContextSingleQuoted
+	ContextCommandSubstitution            = ast.ContextCommandSubstitution
+	ContextBacktick                       = ast.ContextBacktick
+	ContextDollarSingleQuoted             = ast.ContextDollarSingleQuoted
+	ContextParameterExpansion             = ast.ContextParameterExpansion
+	ContextDoubleQuotedParameterExpansion = ast.ContextDoubleQuotedParameterExpansion
 
 	ValueLiteral     = ast.ValueLiteral
 	ValuePlaceholder = ast.ValuePlaceholder
@@ -321,3 +321,90 @@ func TestRender_BareLeadingTildeAfterEmptyToken(t *testing.T) {
 		t.Fatalf("got %q, want %q", got, want)
 	}
 }
+
+// [EVT-MEU-9]
+func TestRender_EncodingContextMatrix(t *testing.T) {
+	secret := "k=é/ ?\xfb\xff#\n"
+	encodings := []struct {
+		modifier string
+		want     string
+	}{
+		{"base64", "az3DqS8gP/v/Iw=="},
+		{"base64url", "az3DqS8gP_v_Iw=="},
+		{"base64_nopad", "az3DqS8gP/v/Iw"},
+		{"hex", "6b3dc3a92f203ffbff23"},
+		{"urlencode", "k%3D%C3%A9%2F%20%3F%FB%FF%23"},
+	}
+	contexts := []struct {
+		name   string
+		prefix string
+		suffix string
+	}{
+		{"Bare", "VAL=", "\n"},
+		{"DoubleQuoted", `VAL="`, "\"\n"},
+		{"SingleQuoted", "VAL='", "'\n"},
+		{"CommandSubstitution", "VAL=$(echo ", ")\n"},
+		{"Backtick", "VAL=`echo ", "`\n"},
+	}
+	for _, enc := range encodings {
+		for _, ctx := range contexts {
+			enc, ctx := enc, ctx
+			t.Run(enc.modifier+"/"+ctx.name, func(t *testing.T) {
+				template := ctx.prefix + "<pass:secret|" + enc.modifier + ">" + ctx.suffix
+				got, err := renderer.RenderString(template, externalResolver{"secret": secret})
+				if err != nil {
+					t.Fatalf("RenderString error: %v", err)
+				}
+				if want := ctx.prefix + enc.want + ctx.suffix; got != want {
+					t.Fatalf("got %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
+
+// [EVT-MEU-9][EVT-MEU-5]
+func TestRender_URLEncodeLeadingTildeEscapedInBare(t *testing.T) {
+	got, err := renderer.RenderString("VAL=<pass:s|urlencode>\n", externalResolver{"s": "~a b"})
+	if err != nil {
+		t.Fatalf("RenderString error: %v", err)
+	}
+	if want := "VAL=\\~a%20b\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+// [EVT-MPU-12]
+func TestRender_EncodingCombinations(t *testing.T) {
+	entry := "  p@ss word \nuser: alice\n"
+	cases := []struct {
+		name     string
+		template string
+		want     string
+		code     string
+	}{
+		{name: "StripThenHex", template: "VAL=<pass:secret|first_line,strip,hex>\n", want: "VAL=7040737320776f7264\n"},
+		{name: "StripLeftThenURLEncode", template: "VAL=<pass:secret|first_line,strip_left,urlencode>\n", want: "VAL=p%40ss%20word%20\n"},
+		{name: "FieldThenBase64URL", template: "VAL=<pass:secret|field=user,base64url>\n", want: "VAL=YWxpY2U=\n"},
+		{name: "FallbackNotEncoded", template: "VAL=<pass:secret|field=host,default=n/a,hex>\n", want: "VAL=n/a\n"},
+		{name: "AllowTab", template: "VAL=<pass:secret|hex,allow_tab>\n", code: "EVE-105-601"},
+		{name: "AllowNewline", template: "VAL=\"<pass:secret|urlencode,allow_newline>\"\n", code: "EVE-105-601"},
+		{name: "BypassEscape", template: "VAL=<pass:secret|base64url,dangerously_bypass_escape>\n", code: "EVE-105-601"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := renderer.RenderString(tc.template, externalResolver{"secret": entry})
+			if tc.code != "" {
+				expectPlaceholderError(t, err, tc.code)
+				return
+			}
+			if err != nil {
+				t.Fatalf("RenderString error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
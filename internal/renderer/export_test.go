@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"strings"
 
+	"envseed/internal/ast"
 	"envseed/internal/sandbox"
 	"envseed/internal/testsupport"
 )
@@ -53,7 +54,7 @@ func ExportSandboxCapture(rendered string, names []string) (map[string]string, e
 }
 
 // Export wrapper for context-local renderers and modifier processing.
-func ExportModifierSet(mods []string) map[string]bool { return modifierSet(mods) }
+func ExportModifierSet(mods []ast.Modifier) map[string]bool { return modifierSet(mods) }
 func ExportRenderSingleQuoted(secret string, mods map[string]bool, line, column int, path string) (string, error) {
 	return renderSingleQuoted(secret, mods, line, column, path)
 }
@@ -2,9 +2,13 @@ package renderer
 
 import (
 	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"envseed/internal/ast"
 	"envseed/internal/parser"
@@ -15,10 +19,54 @@ type Resolver interface {
 	Resolve(path string) (string, error)
 }
 
+// SchemeResolver resolves placeholders whose scheme is not pass. Resolvers that
+// only serve `<pass:...>` placeholders need not implement it.
+type SchemeResolver interface {
+	ResolveScheme(scheme, path string) (string, error)
+}
+
+// PlaceholderResolver resolves placeholders from their full token, so that
+// resolvers can see the modifiers and quoting context of each occurrence.
+// When implemented it takes precedence over Resolve and ResolveScheme.
+type PlaceholderResolver interface {
+	ResolvePlaceholder(tok ast.ValueToken) (string, error)
+}
+
 type renderObserver interface {
 	RecordRendered(path, rendered string)
 }
 
+// MissingValueError is implemented by resolver errors that can tell a missing
+// value (absent entry, variable, file or key) from a backend failure.
+// Placeholders marked `optional` or `default=...` fall back only when
+// resolution fails with a missing value.
+type MissingValueError interface {
+	error
+	MissingValue() bool
+}
+
+// Fallback describes a placeholder rendered from its fallback because its value
+// was missing: the `default=` argument, or an empty value for `optional`.
+type Fallback struct {
+	// File is the template of the assignment, when known.
+	File   string
+	Line   int
+	Column int
+	// Name is the assigned variable.
+	Name   string
+	Scheme string
+	Path   string
+	// Default reports whether a `default=` value was used rather than an
+	// empty value.
+	Default bool
+}
+
+// FallbackObserver is implemented by resolvers that want to know which
+// placeholders fell back.
+type FallbackObserver interface {
+	RecordFallback(fb Fallback)
+}
+
 // RenderElements renders parsed elements using the provided resolver.
 func RenderElements(elements []ast.Element, resolver Resolver) (string, error) {
 	var out strings.Builder
@@ -67,8 +115,60 @@ func RenderString(input string, resolver Resolver) (string, error) {
 	return RenderElements(elems, resolver)
 }
 
+// CheckValue reports whether value, resolved for the placeholder tok, would
+// render: it applies the modifiers of tok and the rules of its context and
+// returns the *PlaceholderError rendering would fail with. Nothing is recorded.
+func CheckValue(tok ast.ValueToken, value string) error {
+	_, _, err := renderSecret(&ast.Assignment{Line: tok.Line}, tok, nil, value, false, false)
+	return err
+}
+
+// CheckContext is CheckValue for a value that is a whole entry rather than the
+// entry tok reads from: selectors and base64_decode, which read a part or an
+// encoding of the entry, are skipped, so only the strip family, encodings and
+// the rules of the context apply.
+func CheckContext(tok ast.ValueToken, value string) error {
+	mods := make([]ast.Modifier, 0, len(tok.Modifiers))
+	for _, m := range tok.Modifiers {
+		if !isSelectorModifier(m.Name) && m.Name != "base64_decode" {
+			mods = append(mods, m)
+		}
+	}
+	tok.Modifiers = mods
+	return CheckValue(tok, value)
+}
+
+// ResolveError reports a placeholder whose value the resolver failed to
+// provide. File is the template of the assignment, when known.
+type ResolveError struct {
+	File   string
+	Line   int
+	Column int
+	Path   string
+	Err    error
+}
+
+// Location returns `FILE:LINE:COLUMN`, or `line N` when the file is unknown.
+func (e *ResolveError) Location() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+	}
+	return fmt.Sprintf("line %d", e.Line)
+}
+
+// Error implements the error interface.
+func (e *ResolveError) Error() string {
+	return fmt.Sprintf("%s: resolve %q: %v", e.Location(), e.Path, e.Err)
+}
+
+// Unwrap returns the resolver error.
+func (e *ResolveError) Unwrap() error {
+	return e.Err
+}
+
 // PlaceholderError captures a rendering violation tied to a specific placeholder.
 type PlaceholderError struct {
+	file       string
 	line       int
 	column     int
 	path       string
@@ -97,6 +197,9 @@ func (e *PlaceholderError) Error() string {
 	if e.column > 0 {
 		location = fmt.Sprintf("%s, column %d", location, e.column)
 	}
+	if e.file != "" {
+		location = fmt.Sprintf("%s:%d:%d", e.file, e.line, e.column)
+	}
 	return fmt.Sprintf("%s: placeholder %q: %s", location, e.path, e.message)
 }
 
@@ -120,6 +223,7 @@ func renderAssignment(assign *ast.Assignment, resolver Resolver) (string, bool,
 	var b strings.Builder
 	dangerousBypassUsed := false
 	b.WriteString(assign.LeadingWhitespace)
+	b.WriteString(assign.Declaration)
 	b.WriteString(assign.Name)
 	switch assign.Operator {
 	case ast.OperatorAssign:
@@ -139,13 +243,22 @@ func renderAssignment(assign *ast.Assignment, resolver Resolver) (string, bool,
 				emittedAny = true
 			}
 		case ast.ValuePlaceholder:
-			secret, err := resolver.Resolve(tok.Path)
+			secret, err := resolveToken(resolver, tok)
+			fallback := false
 			if err != nil {
-				return "", false, fmt.Errorf("line %d: resolve %q: %w", assign.Line, tok.Path, err)
+				value, optional := fallbackValue(tok)
+				if !optional || !isMissingValue(err) {
+					return "", false, &ResolveError{File: assign.File, Line: assign.Line, Column: tok.Column, Path: tok.Path, Err: err}
+				}
+				secret, fallback = value, true
 			}
 			atStart := !emittedAny && i == 0 || !emittedAny
-			rendered, dangerous, err := renderSecret(assign, tok, resolver, secret, atStart)
+			rendered, dangerous, err := renderSecret(assign, tok, resolver, secret, fallback, atStart)
 			if err != nil {
+				var perr *PlaceholderError
+				if errors.As(err, &perr) {
+					perr.file = assign.File
+				}
 				return "", false, err
 			}
 			if dangerous {
@@ -166,7 +279,24 @@ func renderAssignment(assign *ast.Assignment, resolver Resolver) (string, bool,
 	return b.String(), dangerousBypassUsed, nil
 }
 
-func renderSecret(assign *ast.Assignment, tok ast.ValueToken, resolver Resolver, secret string, atStart bool) (string, bool, error) {
+// resolveToken dispatches a placeholder to the resolver serving its scheme.
+func resolveToken(resolver Resolver, tok ast.ValueToken) (string, error) {
+	if placeholder, ok := resolver.(PlaceholderResolver); ok {
+		return placeholder.ResolvePlaceholder(tok)
+	}
+	if tok.Scheme == "" || tok.Scheme == ast.SchemePass {
+		return resolver.Resolve(tok.Path)
+	}
+	schemed, ok := resolver.(SchemeResolver)
+	if !ok {
+		return "", fmt.Errorf("no resolver for placeholder scheme %q", tok.Scheme)
+	}
+	return schemed.ResolveScheme(tok.Scheme, tok.Path)
+}
+
+// renderSecret applies the modifiers of tok to secret and escapes it for the
+// placeholder's context. A fallback value (see fallbackValue) is only escaped.
+func renderSecret(assign *ast.Assignment, tok ast.ValueToken, resolver Resolver, secret string, fallback, atStart bool) (string, bool, error) {
 	mods := modifierSet(tok.Modifiers)
 	var observer renderObserver
 	if rec, ok := resolver.(renderObserver); ok {
@@ -175,14 +305,18 @@ func renderSecret(assign *ast.Assignment, tok ast.ValueToken, resolver Resolver,
 	if mods["dangerously_bypass_escape"] && len(mods) > 1 {
 		return "", false, newPlaceholderError(assign.Line, tok.Column, tok.Path, "EVE-105-601", "invalid placeholder modifier combination")
 	}
-	if mods["base64"] {
-		for mod := range mods {
-			if mod == "base64" {
-				continue
-			}
-			return "", false, newPlaceholderError(assign.Line, tok.Column, tok.Path, "EVE-105-601", "invalid placeholder modifier combination")
+	if encodesLast(tok.Modifiers) && (mods["allow_tab"] || mods["allow_newline"]) {
+		return "", false, newPlaceholderError(assign.Line, tok.Column, tok.Path, "EVE-105-601", "invalid placeholder modifier combination")
+	}
+	selectors := 0
+	for mod := range mods {
+		if isSelectorModifier(mod) {
+			selectors++
 		}
 	}
+	if selectors > 1 {
+		return "", false, newPlaceholderError(assign.Line, tok.Column, tok.Path, "EVE-105-602", "conflicting selector modifiers")
+	}
 	if mods["dangerously_bypass_escape"] {
 		if observer != nil {
 			observer.RecordRendered(tok.Path, secret)
@@ -190,16 +324,17 @@ func renderSecret(assign *ast.Assignment, tok ast.ValueToken, resolver Resolver,
 		return secret, true, nil
 	}
 
-	// Default EOF newline normalization (Section 5.1):
-	// Remove exactly one trailing logical newline (LF or CRLF) at EOF, if present.
-	// Internal newlines and additional trailing newlines beyond the last one are preserved.
-	secret = normalizeEOFNewline(secret)
-
-	// Apply strip-family modifiers (Section 5.2) after normalization.
-	secret = applyStripModifiers(secret, mods)
-
-	if mods["base64"] {
-		secret = base64.StdEncoding.EncodeToString([]byte(secret))
+	if !fallback {
+		var err error
+		secret, fallback, err = transformSecret(assign, tok, secret)
+		if err != nil {
+			return "", false, err
+		}
+	}
+	if fallback {
+		if rec, ok := resolver.(FallbackObserver); ok {
+			rec.RecordFallback(Fallback{File: assign.File, Line: assign.Line, Column: tok.Column, Name: assign.Name, Scheme: tok.Scheme, Path: tok.Path, Default: mods["default"]})
+		}
 	}
 
 	switch tok.Context {
@@ -248,11 +383,184 @@ func renderSecret(assign *ast.Assignment, tok ast.ValueToken, resolver Resolver,
 			observer.RecordRendered(tok.Path, rendered)
 		}
 		return rendered, false, nil
+	case ast.ContextParameterExpansion, ast.ContextDoubleQuotedParameterExpansion:
+		rendered, err := renderParameterExpansion(secret, mods, assign.Line, tok.Column, tok.Path, tok.Context == ast.ContextDoubleQuotedParameterExpansion)
+		if err != nil {
+			return "", false, err
+		}
+		if observer != nil {
+			observer.RecordRendered(tok.Path, rendered)
+		}
+		return rendered, false, nil
+	case ast.ContextDollarSingleQuoted:
+		rendered := renderDollarSingleQuoted(secret)
+		if observer != nil {
+			observer.RecordRendered(tok.Path, rendered)
+		}
+		return rendered, false, nil
 	default:
 		return "", false, fmt.Errorf("line %d: unsupported placeholder context", assign.Line)
 	}
 }
 
+// transformSecret applies EOF newline normalization and then the value
+// transforms of tok (selectors, strip family, base64_decode, encodings) left
+// to right. A selection that finds no line or field yields the fallback value
+// of an optional placeholder and reports true. A decoded value that is not
+// re-encoded must be text: NUL or invalid UTF-8 fails.
+func transformSecret(assign *ast.Assignment, tok ast.ValueToken, secret string) (string, bool, error) {
+	// Default EOF newline normalization (Section 5.1):
+	// Remove exactly one trailing logical newline (LF or CRLF) at EOF, if present.
+	// Internal newlines and additional trailing newlines beyond the last one are preserved.
+	secret = normalizeEOFNewline(secret)
+
+	// Apply the modifier pipeline in template order (Section 5.2).
+	decoded := false
+	for _, mod := range tok.Modifiers {
+		switch {
+		case isSelectorModifier(mod.Name):
+			selected, err := selectEntryPart(secret, mod, assign.Line, tok.Column, tok.Path)
+			if err != nil {
+				if value, optional := fallbackValue(tok); optional {
+					return value, true, nil
+				}
+				return "", false, err
+			}
+			secret = selected
+		case isStripModifier(mod.Name):
+			secret = applyStripModifier(secret, mod.Name)
+		case mod.Name == "base64_decode":
+			plain, err := decodeBase64(secret)
+			if err != nil {
+				return "", false, newPlaceholderError(assign.Line, tok.Column, tok.Path, "EVE-105-901", "value is not valid base64")
+			}
+			secret, decoded = plain, true
+		case isEncodingModifier(mod.Name):
+			secret, decoded = encodeSecret(secret, mod.Name), false
+		}
+	}
+	if decoded && (strings.IndexByte(secret, 0) >= 0 || !utf8.ValidString(secret)) {
+		return "", false, newPlaceholderError(assign.Line, tok.Column, tok.Path, "EVE-105-902", "decoded value contains NUL or invalid UTF-8")
+	}
+	return secret, false, nil
+}
+
+// decodeBase64 decodes standard Base64, ignoring SPACE, TAB, CR and LF (so
+// wrapped output of base64(1) decodes) and accepting missing padding.
+func decodeBase64(s string) (string, error) {
+	s = strings.Map(func(r rune) rune {
+		if isStripWhitespace(r) {
+			return -1
+		}
+		return r
+	}, s)
+	enc := base64.RawStdEncoding
+	if strings.HasSuffix(s, "=") {
+		enc = base64.StdEncoding
+	}
+	plain, err := enc.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// encodesLast reports whether the last value transform of mods is an
+// encoding, so the rendered value cannot contain TAB or newlines.
+func encodesLast(mods []ast.Modifier) bool {
+	last := ""
+	for _, m := range mods {
+		if isSelectorModifier(m.Name) || isStripModifier(m.Name) || m.Name == "base64_decode" || isEncodingModifier(m.Name) {
+			last = m.Name
+		}
+	}
+	return isEncodingModifier(last)
+}
+
+// isStripModifier reports whether the modifier named name belongs to the strip
+// family.
+func isStripModifier(name string) bool {
+	return name == "strip" || name == "strip_left" || name == "strip_right"
+}
+
+// isEncodingModifier reports whether the modifier named name encodes the
+// value: `base64`, `base64url`, `base64_nopad`, `hex` or `urlencode`.
+func isEncodingModifier(name string) bool {
+	switch name {
+	case "base64", "base64url", "base64_nopad", "hex", "urlencode":
+		return true
+	}
+	return false
+}
+
+// encodeSecret applies the encoding modifier named name to secret. Every
+// encoding emits ASCII only, without whitespace or line breaks.
+func encodeSecret(secret, name string) string {
+	switch name {
+	case "base64":
+		return base64.StdEncoding.EncodeToString([]byte(secret))
+	case "base64url":
+		return base64.URLEncoding.EncodeToString([]byte(secret))
+	case "base64_nopad":
+		return base64.RawStdEncoding.EncodeToString([]byte(secret))
+	case "hex":
+		return hex.EncodeToString([]byte(secret))
+	case "urlencode":
+		return urlEncode(secret)
+	}
+	return secret
+}
+
+// urlEncode percent-encodes every byte of s outside the RFC 3986 unreserved
+// set `A-Za-z0-9-._~`, using uppercase hex digits. Unlike url.QueryEscape,
+// SPACE becomes %20, so the result is safe in any URL component.
+func urlEncode(s string) string {
+	const upperhex = "0123456789ABCDEF"
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(upperhex[c>>4])
+			b.WriteByte(upperhex[c&0x0f])
+		}
+	}
+	return b.String()
+}
+
+// fallbackValue returns the value rendered when the value of tok is missing and
+// reports whether tok is optional: the `default=` argument, or an empty value
+// for `optional`.
+func fallbackValue(tok ast.ValueToken) (string, bool) {
+	optional := false
+	for _, m := range tok.Modifiers {
+		switch m.Name {
+		case "default":
+			return m.Arg, true
+		case "optional":
+			optional = true
+		}
+	}
+	return "", optional
+}
+
+// isFallbackModifier reports whether the modifier named name makes a
+// placeholder optional.
+func isFallbackModifier(name string) bool {
+	return name == "optional" || name == "default"
+}
+
+// isMissingValue reports whether err reports a missing value.
+func isMissingValue(err error) bool {
+	var missing MissingValueError
+	return errors.As(err, &missing) && missing.MissingValue()
+}
+
 // normalizeEOFNewline removes exactly one trailing logical newline at EOF.
 // A logical newline is either LF ("\n") or CRLF ("\r\n"). A standalone CR is not
 // treated as a logical newline for normalization purposes.
@@ -424,22 +732,137 @@ func renderBacktick(secret string, mods map[string]bool, line, column int, path
 	return b.String(), nil
 }
 
-func modifierSet(mods []string) map[string]bool {
+// renderParameterExpansion escapes secret for the word of a `${NAME:-word}`
+// expansion. `}`, `\`, `$`, backticks and `"` are escaped in both forms; an
+// unquoted word also escapes `'` and every `~`, which assignment tilde
+// expansion would expand after `:`. Inside double quotes `\'` keeps its
+// backslash and bash still pairs a bare `'`, so `'` cannot be written there.
+func renderParameterExpansion(secret string, mods map[string]bool, line, column int, path string, quoted bool) (string, error) {
+	allowNewline := mods["allow_newline"]
+	allowTab := mods["allow_tab"]
+	var b strings.Builder
+	for _, r := range secret {
+		switch r {
+		case '}', '\\', '$', '`', '"':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\'':
+			if quoted {
+				return "", newPlaceholderError(line, column, path, "EVE-105-1004", "single quote not permitted in double-quoted parameter expansion placeholder")
+			}
+			b.WriteString(`\'`)
+		case '~':
+			if !quoted {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		case '\n', '\r':
+			if !allowNewline {
+				return "", newPlaceholderError(line, column, path, "EVE-105-1001", "newline not permitted in parameter expansion placeholder")
+			}
+			b.WriteRune(r)
+		case '\t':
+			if !allowTab {
+				return "", newPlaceholderError(line, column, path, "EVE-105-1002", "TAB not permitted in parameter expansion placeholder")
+			}
+			b.WriteRune(r)
+		default:
+			if isControlRune(r) {
+				return "", newPlaceholderError(line, column, path, "EVE-105-1003", "control character U+%04X not permitted in parameter expansion placeholder", r)
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), nil
+}
+
+// renderDollarSingleQuoted escapes secret for an ANSI-C `$'...'` body.
+// Printable ASCII is kept as is, except `\` and `'`; every other byte is
+// written as a named escape or `\xHH`, so any value round-trips. Resolvers
+// reject NUL (EVE-104-301, EVE-104-302), which `$'...'` cannot carry, and
+// `allow_tab`/`allow_newline` have nothing to relax here.
+func renderDollarSingleQuoted(secret string) string {
+	var b strings.Builder
+	for i := 0; i < len(secret); i++ {
+		c := secret[i]
+		switch c {
+		case '\\', '\'':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if c < 0x20 || c >= 0x7f {
+				fmt.Fprintf(&b, `\x%02X`, c)
+				continue
+			}
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// modifierSet returns the names of mods. `gen_len` and `gen_charset` only
+// configure `sync --generate-missing` and are left out, so rendering ignores
+// them.
+func modifierSet(mods []ast.Modifier) map[string]bool {
 	set := make(map[string]bool, len(mods))
 	for _, m := range mods {
-		set[m] = true
+		if m.Name == "gen_len" || m.Name == "gen_charset" {
+			continue
+		}
+		set[m.Name] = true
 	}
 	return set
 }
 
-func applyStripModifiers(secret string, mods map[string]bool) string {
-	if !(mods["strip"] || mods["strip_left"] || mods["strip_right"]) {
-		return secret
+// isSelectorModifier reports whether the modifier named name selects part of
+// a multi-line entry: `first_line`, `line=N` or `field=NAME`.
+func isSelectorModifier(name string) bool {
+	return name == "first_line" || name == "line" || name == "field"
+}
+
+// selectEntryPart applies the selector modifier mod to a normalized value.
+// Lines are separated by LF; a trailing CR of the selected line is dropped.
+// `field=NAME` matches the first `NAME: value` line after line 1, comparing
+// NAME case-insensitively.
+func selectEntryPart(secret string, mod ast.Modifier, line, column int, path string) (string, error) {
+	switch mod.Name {
+	case "first_line":
+		first, _, _ := strings.Cut(secret, "\n")
+		return strings.TrimSuffix(first, "\r"), nil
+	case "line":
+		n, _ := strconv.Atoi(mod.Arg)
+		lines := strings.Split(secret, "\n")
+		if n < 1 || n > len(lines) {
+			return "", newPlaceholderError(line, column, path, "EVE-105-801", "entry has no line %d", n)
+		}
+		return strings.TrimSuffix(lines[n-1], "\r"), nil
+	case "field":
+		name := mod.Arg
+		lines := strings.Split(secret, "\n")
+		for _, l := range lines[1:] {
+			key, value, ok := strings.Cut(strings.TrimSuffix(l, "\r"), ":")
+			if ok && strings.EqualFold(strings.TrimSpace(key), name) {
+				return strings.TrimLeft(value, " \t"), nil
+			}
+		}
+		return "", newPlaceholderError(line, column, path, "EVE-105-802", "entry has no field %q", name)
 	}
-	if mods["strip"] || mods["strip_left"] {
+	return secret, nil
+}
+
+// applyStripModifier removes the whitespace named by the strip-family
+// modifier name.
+func applyStripModifier(secret, name string) string {
+	if name == "strip" || name == "strip_left" {
 		secret = strings.TrimLeftFunc(secret, isStripWhitespace)
 	}
-	if mods["strip"] || mods["strip_right"] {
+	if name == "strip" || name == "strip_right" {
 		secret = strings.TrimRightFunc(secret, isStripWhitespace)
 	}
 	return secret
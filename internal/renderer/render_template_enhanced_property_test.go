@@ -379,15 +379,9 @@ func buildEnhancedTemplate(r *rand.Rand, state *enhancedState, mode enhancedMode
 				}
 				modsSet := renderer.ExportModifierSet(tok.Modifiers)
 				// Detect invalid modifier combinations consistent with renderer rules
-				if modsSet["base64"] {
-					count := 0
-					for range modsSet {
-						count++
-					}
-					if count > 1 {
-						expect = propertyExpectation{ShouldErr: true, Phase: failurePhaseRender, DetailCode: "EVE-105-601"}
-						break outer
-					}
+				if modsSet["base64"] && (modsSet["allow_tab"] || modsSet["allow_newline"] || modsSet["dangerously_bypass_escape"]) {
+					expect = propertyExpectation{ShouldErr: true, Phase: failurePhaseRender, DetailCode: "EVE-105-601"}
+					break outer
 				}
 				if modsSet["dangerously_bypass_escape"] {
 					continue
@@ -513,7 +507,7 @@ func lineEnhancedBase64InvalidCombo(r *rand.Rand, state *enhancedState, mode enh
 	b.WriteString(path)
 	b.WriteString("|base64,allow_tab>")
 	b.WriteString("\n")
-	// Invalid combination: base64 MUST be single-only (no other modifiers).
+	// Invalid combination: an encoded value admits no allow_* modifier.
 	return enhancedLine{
 		text:      b.String(),
 		resolver:  externalResolver{path: "x"},
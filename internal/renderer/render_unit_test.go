@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"envseed/internal/ast"
 	"envseed/internal/parser"
 	"envseed/internal/renderer"
 	"envseed/internal/sandbox"
@@ -146,6 +147,73 @@ func TestRender_SingleQuotedRejectsQuote(t *testing.T) {
 	expectPlaceholderError(t, err, "EVE-105-101")
 }
 
+// [EVT-MEU-10]
+func TestRender_DollarSingleQuotedEscapesEveryByte(t *testing.T) {
+	input := "RAW=$'<pass:secret|allow_tab>'\n"
+	secret := "it's\x01\x7f\r\n\tcafé\\x\"$`"
+	out, err := renderer.RenderString(input, externalResolver{"secret": secret})
+	if err != nil {
+		t.Fatalf("RenderString error: %v", err)
+	}
+	want := `RAW=$'it\'s\x01\x7F\r\n\tcaf\xC3\xA9\\x"$` + "`'\n"
+	if out != want {
+		t.Fatalf("rendered output = %q, want %q", out, want)
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(out, "RAW=$'"), "'\n")
+	decoded, err := testsupport.DecodeBashDollarSingleQuoted(body)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if decoded != secret {
+		t.Fatalf("decoded = %q, want %q", decoded, secret)
+	}
+	if _, err := parser.Parse(out); err != nil {
+		t.Fatalf("re-parse failure: %v", err)
+	}
+	if err := testsupport.BashValidate(out); err != nil {
+		t.Fatalf("bash -n validation failed: %v", err)
+	}
+}
+
+// [EVT-MEU-11]
+func TestRender_ParameterExpansionEscaping(t *testing.T) {
+	secret := "a}b\"c$d\\e`f~g h#i"
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"HOST=${HOST:-<pass:secret>}\n", "HOST=${HOST:-a\\}b\\\"c\\$d\\\\e\\`f\\~g h#i}\n"},
+		{"HOST=\"${HOST:-<pass:secret>}\"\n", "HOST=\"${HOST:-a\\}b\\\"c\\$d\\\\e\\`f~g h#i}\"\n"},
+	}
+	for _, tc := range cases {
+		out, err := renderer.RenderString(tc.input, externalResolver{"secret": secret})
+		if err != nil {
+			t.Fatalf("RenderString(%q) error: %v", tc.input, err)
+		}
+		if out != tc.want {
+			t.Fatalf("RenderString(%q) = %q, want %q", tc.input, out, tc.want)
+		}
+		if err := testsupport.BashValidate(out); err != nil {
+			t.Fatalf("bash -n validation failed: %v", err)
+		}
+	}
+
+	out, err := renderer.RenderString("HOST=${HOST:-<pass:secret>}\n", externalResolver{"secret": "O'Connor"})
+	if err != nil || out != "HOST=${HOST:-O\\'Connor}\n" {
+		t.Fatalf("RenderString() = %q, %v", out, err)
+	}
+	_, err = renderer.RenderString("HOST=\"${HOST:-<pass:secret>}\"\n", externalResolver{"secret": "O'Connor"})
+	expectPlaceholderError(t, err, "EVE-105-1004")
+
+	input := "HOST=${HOST:-<pass:secret>}\n"
+	_, err = renderer.RenderString(input, externalResolver{"secret": "a\nb"})
+	expectPlaceholderError(t, err, "EVE-105-1001")
+	_, err = renderer.RenderString(input, externalResolver{"secret": "a\tb"})
+	expectPlaceholderError(t, err, "EVE-105-1002")
+	_, err = renderer.RenderString(input, externalResolver{"secret": "a\x01b"})
+	expectPlaceholderError(t, err, "EVE-105-1003")
+}
+
 // [EVT-MWP-4]
 func TestRender_SingleQuotedRejectsNewline(t *testing.T) {
 	input := "RAW='<pass:secret>'\n"
@@ -450,19 +518,155 @@ func TestRender_CRLFAllowance(t *testing.T) {
 	}
 }
 
-// [EVT-MUP-1]
-func TestRender_InvalidModifierCombination(t *testing.T) {
-	inputs := []string{
-		"VAL=<pass:secret|base64,strip>\n",
-		"VAL=<pass:secret|base64,strip_left>\n",
-		"VAL=<pass:secret|base64,strip_right>\n",
+// [EVT-MPU-13]
+func TestRender_ModifierPipelineOrder(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		secret   string
+		want     string
+	}{
+		{name: "StripThenBase64", template: "VAL=<pass:secret|strip,base64>\n", secret: "  value \n", want: "VAL=dmFsdWU=\n"},
+		{name: "StripLeftThenBase64", template: "VAL=<pass:secret|strip_left,base64>\n", secret: "  value\n", want: "VAL=dmFsdWU=\n"},
+		{name: "Base64ThenURLEncode", template: "VAL=<pass:secret|base64,urlencode>\n", secret: "\xfb\xff#", want: "VAL=%2B%2F8j\n"},
+		{name: "HexThenBase64", template: "VAL=<pass:secret|hex,base64>\n", secret: "hi", want: "VAL=Njg2OQ==\n"},
+		{name: "SelectStripEncodeChain", template: "VAL=<pass:secret|first_line,strip,base64,hex>\n", secret: " p@ss word\nuser: alice\n", want: "VAL=6345427a6379423362334a6b\n"},
+		{name: "FallbackFlagFirst", template: "VAL=<pass:secret|optional,strip,hex>\n", secret: " a\n", want: "VAL=61\n"},
+		{name: "SelectThenStrip", template: "VAL=\"<pass:secret|allow_tab,first_line,strip_right>\"\n", secret: "a\tb \nc\n", want: "VAL=\"a\tb\"\n"},
+		{name: "StripThenSelect", template: "VAL=\"<pass:secret|strip_right,first_line,allow_tab>\"\n", secret: "a\tb \nc\n", want: "VAL=\"a\tb \"\n"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := renderer.RenderString(tc.template, externalResolver{"secret": tc.secret})
+			if err != nil {
+				t.Fatalf("RenderString error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// [EVT-MPU-14]
+func TestRender_Base64Decode(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		secret   string
+		want     string
+	}{
+		{name: "WrappedPEM", template: "CERT=\"<pass:secret|base64_decode,allow_newline>\"\n", secret: "LS0tLS1CRUdJTiBDRVJULS0tLS0K\nTUlJQgotLS0tLUVORCBDRVJULS0tLS0K\n", want: "CERT=\"-----BEGIN CERT-----\nMIIB\n-----END CERT-----\n\"\n"},
+		{name: "Unpadded", template: "VAL=<pass:secret|base64_decode>\n", secret: "aGk", want: "VAL=hi\n"},
+		{name: "DecodeThenStrip", template: "VAL=<pass:secret|base64_decode,strip>\n", secret: "ICB0b2sgCg==", want: "VAL=tok\n"},
+		{name: "SelectThenDecode", template: "VAL=<pass:secret|first_line,base64_decode>\n", secret: "aGk=\nnote: encoded\n", want: "VAL=hi\n"},
+		{name: "BinaryReEncoded", template: "VAL=<pass:secret|base64_decode,hex>\n", secret: "YQBi", want: "VAL=610062\n"},
+		{name: "BinaryReEncodedURL", template: "VAL=<pass:secret|base64_decode,base64url>\n", secret: "//4=", want: "VAL=__4=\n"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := renderer.RenderString(tc.template, externalResolver{"secret": tc.secret})
+			if err != nil {
+				t.Fatalf("RenderString error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// [EVT-MPU-14]
+func TestRender_Base64DecodeErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		secret   string
+		code     string
+	}{
+		{name: "InvalidCharacter", template: "VAL=<pass:secret|base64_decode>\n", secret: "aGk*", code: "EVE-105-901"},
+		{name: "TruncatedQuantum", template: "VAL=<pass:secret|base64_decode>\n", secret: "aGkxa", code: "EVE-105-901"},
+		{name: "ExcessPadding", template: "VAL=<pass:secret|base64_decode>\n", secret: "aGk===", code: "EVE-105-901"},
+		{name: "URLAlphabet", template: "VAL=<pass:secret|base64_decode>\n", secret: "__4=", code: "EVE-105-901"},
+		{name: "DecodedNUL", template: "VAL=\"<pass:secret|base64_decode>\"\n", secret: "YQBi", code: "EVE-105-902"},
+		{name: "DecodedInvalidUTF8", template: "VAL=\"<pass:secret|base64_decode>\"\n", secret: "//4=", code: "EVE-105-902"},
+		{name: "DecodedBinaryStripped", template: "VAL=\"<pass:secret|base64_decode,strip>\"\n", secret: "//4=", code: "EVE-105-902"},
+		{name: "DecodedNewlineNeedsAllow", template: "VAL=\"<pass:secret|base64_decode>\"\n", secret: "bGluZTEKbGluZTI=", code: "EVE-105-201"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := renderer.RenderString(tc.template, externalResolver{"secret": tc.secret})
+			expectPlaceholderError(t, err, tc.code)
+		})
+	}
+}
+
+// [EVT-MPU-15]
+func TestRender_GenModifiersIgnored(t *testing.T) {
+	got, err := renderer.RenderString("A=<pass:secret|gen_len=8,dangerously_bypass_escape>\nB=<pass:secret|gen_charset=hex,base64>\n", externalResolver{"secret": "a b"})
+	if err != nil {
+		t.Fatalf("RenderString error: %v", err)
+	}
+	if want := "A=a b\nB=YSBi\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
 	}
-	for _, input := range inputs {
-		_, err := renderer.RenderString(input, externalResolver{"secret": "value"})
-		expectPlaceholderError(t, err, "EVE-105-601")
+}
+
+// [EVT-MPU-15]
+func TestCheckValue(t *testing.T) {
+	elems, err := parser.Parse("A='<pass:k>'\nB=\"<pass:k|first_line>\"\nC=<pass:k|line=2>\nD=<pass:k|line=2,optional>\n")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	token := func(i int) ast.ValueToken {
+		for _, tok := range elems[i].Assignment.ValueTokens {
+			if tok.Kind == ast.ValuePlaceholder {
+				return tok
+			}
+		}
+		t.Fatalf("line %d has no placeholder", i+1)
+		return ast.ValueToken{}
+	}
+	expectPlaceholderError(t, renderer.CheckValue(token(0), "it's"), "EVE-105-101")
+	if err := renderer.CheckValue(token(1), "p@ss$word"); err != nil {
+		t.Fatalf("CheckValue(double-quoted) error: %v", err)
+	}
+	expectPlaceholderError(t, renderer.CheckValue(token(2), "one line"), "EVE-105-801")
+	if err := renderer.CheckValue(token(3), "one line"); err != nil {
+		t.Fatalf("CheckValue(optional) error: %v", err)
 	}
 }
 
+// [EVT-MPU-15][EVT-MZU-20]
+func TestCheckContextSkipsSelectorsAndDecoders(t *testing.T) {
+	elems, err := parser.Parse("A=<pass:k|field=user>\nB=<pass:k|line=3>\nC=<pass:k|base64_decode>\nD='<pass:k|field=user>'\n")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	token := func(i int) ast.ValueToken {
+		for _, tok := range elems[i].Assignment.ValueTokens {
+			if tok.Kind == ast.ValuePlaceholder {
+				return tok
+			}
+		}
+		t.Fatalf("line %d has no placeholder", i+1)
+		return ast.ValueToken{}
+	}
+	for i := range 3 {
+		tok := token(i)
+		if err := renderer.CheckValue(tok, "abc-def"); err == nil {
+			t.Fatalf("line %d: CheckValue succeeded, want a selection or decoding error", i+1)
+		}
+		if err := renderer.CheckContext(tok, "abc-def"); err != nil {
+			t.Fatalf("line %d: CheckContext error: %v", i+1, err)
+		}
+	}
+	expectPlaceholderError(t, renderer.CheckContext(token(3), "it's"), "EVE-105-101")
+}
+
 // [EVT-MUP-1]
 func TestRender_Base64InvalidCombinations(t *testing.T) {
 	t.Helper()
@@ -936,6 +1140,183 @@ MESSAGE="<pass:message|allow_tab>"
 	}
 }
 
+type schemeMapResolver struct {
+	externalResolver
+	schemes map[string]map[string]string
+}
+
+func (r schemeMapResolver) ResolveScheme(scheme, path string) (string, error) {
+	v, ok := r.schemes[scheme][path]
+	if !ok {
+		return "", errors.New("missing value")
+	}
+	return v, nil
+}
+
+// [EVT-MZU-7]
+func TestRender_SchemeResolverDispatch(t *testing.T) {
+	input := "A=<pass:key>\nB=\"<env:key>\"\nC=<file:key|strip>\n"
+	resolver := schemeMapResolver{
+		externalResolver: externalResolver{"key": "from-pass"},
+		schemes: map[string]map[string]string{
+			"env":  {"key": "from env"},
+			"file": {"key": "from-file\n"},
+		},
+	}
+	got, err := renderer.RenderString(input, resolver)
+	if err != nil {
+		t.Fatalf("RenderString error: %v", err)
+	}
+	want := "A=from-pass\nB=\"from env\"\nC=from-file\n"
+	if got != want {
+		t.Fatalf("rendered output = %q, want %q", got, want)
+	}
+}
+
+// [EVT-MZU-7]
+func TestRender_SchemeWithoutSchemeResolverFails(t *testing.T) {
+	_, err := renderer.RenderString("A=<env:HOME>\n", externalResolver{"HOME": "/root"})
+	if err == nil {
+		t.Fatal("expected error for scheme without SchemeResolver")
+	}
+}
+
+type placeholderRecorder struct {
+	externalResolver
+	seen []ast.ValueToken
+}
+
+func (r *placeholderRecorder) ResolvePlaceholder(tok ast.ValueToken) (string, error) {
+	r.seen = append(r.seen, tok)
+	return tok.Scheme + ":" + tok.Path, nil
+}
+
+// [EVT-MZU-7][EVT-MZU-15]
+func TestRender_PlaceholderResolverSeesToken(t *testing.T) {
+	resolver := &placeholderRecorder{externalResolver: externalResolver{"key": "from-pass"}}
+	got, err := renderer.RenderString("A=<pass:key>\nB=\"<x-kc:item|strip>\"\n", resolver)
+	if err != nil {
+		t.Fatalf("RenderString error: %v", err)
+	}
+	if want := "A=pass:key\nB=\"x-kc:item\"\n"; got != want {
+		t.Fatalf("rendered output = %q, want %q", got, want)
+	}
+	if len(resolver.seen) != 2 {
+		t.Fatalf("ResolvePlaceholder calls = %d, want 2", len(resolver.seen))
+	}
+	if tok := resolver.seen[1]; tok.Context != ast.ContextDoubleQuoted || len(tok.Modifiers) != 1 || tok.Modifiers[0].Name != "strip" {
+		t.Fatalf("token = %+v, want double-quoted with strip", tok)
+	}
+}
+
+// missingError is a resolver error that reports a missing value.
+type missingError struct{ path string }
+
+func (e missingError) Error() string      { return "missing " + e.path }
+func (e missingError) MissingValue() bool { return true }
+
+// fallbackRecorder resolves from its map, answers missingError for other
+// paths, and records fallbacks.
+type fallbackRecorder struct {
+	values    map[string]string
+	fallbacks []renderer.Fallback
+}
+
+func (r *fallbackRecorder) Resolve(path string) (string, error) {
+	if path == "broken" {
+		return "", errors.New("backend failure")
+	}
+	v, ok := r.values[path]
+	if !ok {
+		return "", missingError{path}
+	}
+	return v, nil
+}
+
+func (r *fallbackRecorder) RecordFallback(fb renderer.Fallback) {
+	r.fallbacks = append(r.fallbacks, fb)
+}
+
+// [EVT-MZU-19]
+func TestRender_OptionalPlaceholderFallback(t *testing.T) {
+	resolver := &fallbackRecorder{values: map[string]string{"site": "pw\nuser: alice\n"}}
+	template := strings.Join([]string{
+		"A=<pass:sentry/dsn|optional>",
+		"B=<pass:flags/key|default=\"a b$c\">",
+		"C='<pass:flags/key|default=\"x y\">'",
+		"D=<pass:site|field=email,default=none@example.com>",
+		"E=<pass:site|field=user,optional>",
+		"F=<pass:missing|base64,default=\"x y\">",
+	}, "\n") + "\n"
+	got, err := renderer.RenderString(template, resolver)
+	if err != nil {
+		t.Fatalf("RenderString error: %v", err)
+	}
+	want := "A=\nB=a\\ b\\$c\nC='x y'\nD=none@example.com\nE=alice\nF=x\\ y\n"
+	if got != want {
+		t.Fatalf("rendered output = %q, want %q", got, want)
+	}
+	wantFallbacks := []renderer.Fallback{
+		{Line: 1, Column: 3, Name: "A", Scheme: "pass", Path: "sentry/dsn"},
+		{Line: 2, Column: 3, Name: "B", Scheme: "pass", Path: "flags/key", Default: true},
+		{Line: 3, Column: 4, Name: "C", Scheme: "pass", Path: "flags/key", Default: true},
+		{Line: 4, Column: 3, Name: "D", Scheme: "pass", Path: "site", Default: true},
+		{Line: 6, Column: 3, Name: "F", Scheme: "pass", Path: "missing", Default: true},
+	}
+	if len(resolver.fallbacks) != len(wantFallbacks) {
+		t.Fatalf("fallbacks = %+v, want %+v", resolver.fallbacks, wantFallbacks)
+	}
+	for i := range wantFallbacks {
+		if resolver.fallbacks[i] != wantFallbacks[i] {
+			t.Fatalf("fallback %d = %+v, want %+v", i, resolver.fallbacks[i], wantFallbacks[i])
+		}
+	}
+}
+
+// [EVT-MZU-19]
+func TestRender_OptionalPlaceholderErrors(t *testing.T) {
+	resolver := &fallbackRecorder{values: map[string]string{"multi": "a\nb"}}
+	// Backend failures are not missing values.
+	if _, err := renderer.RenderString("A=<pass:broken|optional>\n", resolver); err == nil || !strings.Contains(err.Error(), "backend failure") {
+		t.Fatalf("expected backend failure, got %v", err)
+	}
+	// Required placeholders still fail on missing values.
+	if _, err := renderer.RenderString("A=<pass:absent>\n", resolver); err == nil {
+		t.Fatal("expected missing value error")
+	}
+	// The fallback is validated for its context like any value.
+	_, err := renderer.RenderString("A=<pass:absent|default=\"a\tb\">\n", resolver)
+	expectPlaceholderError(t, err, "EVE-105-502")
+	// dangerously_bypass_escape stays exclusive.
+	_, err = renderer.RenderString("A=<pass:multi|dangerously_bypass_escape,optional>\n", resolver)
+	expectPlaceholderError(t, err, "EVE-105-601")
+}
+
+// [EVT-MGU-7]
+func TestRender_DeclarationPrefixKeepsEscaping(t *testing.T) {
+	resolver := externalResolver{"a": "it's $HOME #1", "b": "x y"}
+	for _, line := range []string{
+		"A=<pass:a>\n",
+		"B=\"<pass:b>\"\n",
+		"C='<pass:b>'\n",
+		"D+=<pass:a>\n",
+	} {
+		plain, err := renderer.RenderString(line, resolver)
+		if err != nil {
+			t.Fatalf("RenderString(%q) error: %v", line, err)
+		}
+		for _, prefix := range []string{"export ", "readonly\t", "declare -x "} {
+			got, err := renderer.RenderString("  "+prefix+line, resolver)
+			if err != nil {
+				t.Fatalf("RenderString(%q) error: %v", prefix+line, err)
+			}
+			if want := "  " + prefix + plain; got != want {
+				t.Fatalf("rendered %q, want %q", got, want)
+			}
+		}
+	}
+}
+
 func compareStringMaps(got, want map[string]string) string {
 	var b strings.Builder
 	for key, wantVal := range want {
@@ -40,7 +40,7 @@ func (g *CoverageGate) ObserveElements(elems []ast.Element) {
 		for _, tok := range e.Assignment.ValueTokens {
 			g.seenContexts[tok.Context] = true
 			for _, m := range tok.Modifiers {
-				g.seenModifiers[m] = true
+				g.seenModifiers[m.Name] = true
 			}
 		}
 	}
@@ -1,5 +1,7 @@
 package testsupport
 
+import "envseed/internal/ast"
+
 // EqualStrings reports whether two string slices have identical length and
 // element order.
 func EqualStrings(a, b []string) bool {
@@ -13,3 +15,12 @@ func EqualStrings(a, b []string) bool {
 	}
 	return true
 }
+
+// ModifierStrings returns the template syntax of each modifier, in order.
+func ModifierStrings(mods []ast.Modifier) []string {
+	out := make([]string, 0, len(mods))
+	for _, m := range mods {
+		out = append(out, m.String())
+	}
+	return out
+}